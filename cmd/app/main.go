@@ -1,45 +1,159 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/aryan9600/service-catalog/internal/api"
 	"github.com/aryan9600/service-catalog/internal/auth"
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/metrics"
 	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/aryan9600/service-catalog/internal/tracing"
 	"github.com/joho/godotenv"
 )
 
+// traceFlushTimeout is how long the remaining spans are given to be exported on shutdown.
+const traceFlushTimeout = 5 * time.Second
+
 func main() {
 	err := godotenv.Load()
 	if err != nil {
 		log.Println("failed to read .env")
 	}
 
-	if err := models.SetDBConfiguration(); err != nil {
+	args := os.Args[1:]
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		os.Exit(printConfig(args[2:]))
+	}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := tracing.SetTracingConfig(cfg.Tracing); err != nil {
 		panic(err)
 	}
+
+	models.SetDBConfiguration(cfg.Database)
 	if err := models.InitDB(); err != nil {
 		panic(err)
 	}
+	if err := metrics.RegisterDB(models.DB); err != nil {
+		panic(err)
+	}
+	if err := models.DB.Use(tracing.GormPlugin{}); err != nil {
+		panic(err)
+	}
 
-	if os.Getenv("AUTO_MIGRATE") == "true" {
+	if cfg.Database.AutoMigrate {
 		log.Println("running migrations...")
 		if err := models.Migrate("", false); err != nil {
 			panic(err)
 		}
 	}
 
-	if err := auth.SetTokenGenerationConfig(); err != nil {
+	auth.SetTokenGenerationConfig(cfg.Auth)
+	if err := models.SetServiceNamingConfig(cfg.Services); err != nil {
+		panic(err)
+	}
+	if err := models.SetSpecStorageConfig(cfg.Specs); err != nil {
 		panic(err)
 	}
+	models.SetSpecCompatibilityConfig(cfg.Specs)
+	models.SetEnvironmentConfig(cfg.Deployments)
+	models.SetPromotionConfig(cfg.Promotion)
 
-	port := os.Getenv("SERVER_PORT")
-	if port == "" {
-		port = "8080"
+	api.SetLogConfig(cfg.Log)
+	api.SetCORSConfig(cfg.CORS)
+	api.SetSecurityHeadersConfig(cfg.Security)
+	api.SetHealthCheckConfig(cfg.HealthCheck)
+	api.SetServerConfig(cfg.Server)
+	auth.SetClientCertificateConfig(cfg.Server.TLS)
+	api.SetRateLimitConfig(cfg.RateLimit)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server, err := api.NewServer(api.NewRouter())
+	if err != nil {
+		log.Fatal(err)
 	}
+	serverErr := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			log.Printf("listening on %s with TLS", server.Addr)
+			serverErr <- server.ListenAndServeTLS("", "")
+			return
+		}
+		log.Printf("listening on %s", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
 
-	router := api.NewRouter()
-	router.Run(fmt.Sprintf(":%s", port))
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("server failed: %v", err)
+			exitCode = 1
+		}
+	case <-ctx.Done():
+		// A second signal kills the process right away.
+		stop()
+		log.Printf("shutting down, waiting up to %s for in-flight requests", api.ShutdownTimeout())
+	}
+	shutdown(server)
+	os.Exit(exitCode)
+}
+
+// printConfig implements the 'config print' command: it prints the configuration
+// loaded with the args, with secrets redacted, followed by its problems if it's invalid.
+func printConfig(args []string) int {
+	cfg, err := config.Load(args)
+	if cfg == nil {
+		log.Println(err)
+		return 2
+	}
+	if printErr := cfg.Print(os.Stdout); printErr != nil {
+		log.Println(printErr)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// shutdown stops accepting connections and waits for in-flight requests to
+// complete, before flushing traces and logs and closing the database pool.
+func shutdown(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), api.ShutdownTimeout())
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("unable to drain in-flight requests: %v", err)
+	}
+
+	// Flushing traces gets its own deadline, since draining may have used up the previous one.
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), traceFlushTimeout)
+	defer cancelFlush()
+	if err := tracing.Shutdown(flushCtx); err != nil {
+		log.Printf("unable to flush traces: %v", err)
+	}
+	if err := api.CloseLog(); err != nil {
+		log.Printf("unable to close log file: %v", err)
+	}
+	if err := models.CloseDB(); err != nil {
+		log.Printf("unable to close database connections: %v", err)
+	}
+	log.Println("shut down")
 }
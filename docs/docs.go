@@ -41,6 +41,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.LoginOutput"
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
                     }
                 }
             }
@@ -71,6 +77,267 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.RegisterOutput"
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/deployments/current": {
+            "get": {
+                "description": "Returns the latest deployment of each service in the catalog to each environment, not only of the user's own services.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List what is currently deployed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only list deployments to this environment, e.g. prod",
+                        "name": "environment",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListCurrentDeploymentsOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/environments": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the environments versions can be deployed to",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListEnvironmentsOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/export": {
+            "get": {
+                "description": "Exports the whole catalog, not only the user's own services.",
+                "produces": [
+                    "application/yaml"
+                ],
+                "summary": "Export all services as Backstage catalog-info.yaml entities.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Always succeeds while the process is able to serve requests.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthOutput"
+                        }
+                    }
+                }
+            }
+        },
+        "/import": {
+            "post": {
+                "description": "The body may contain one or many YAML documents. Only entities of kind 'Component' are imported,\nothers are reported as skipped. Services are matched by name and updated if they already exist.",
+                "consumes": [
+                    "application/yaml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Import services from Backstage catalog-info.yaml entities.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Report the changes without saving them",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ImportOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Checks the database connection, that all migrations were run and that the JWT signing key is configured.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthOutput"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthOutput"
+                        }
+                    }
+                }
+            }
+        },
+        "/schemas/custom-fields": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the JSON Schema which custom fields of services must conform to.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CustomFieldSchemaOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Only admins can register schemas. The schema is rejected with a 409 if\nthe custom fields of existing services don't conform to it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Register the JSON Schema which custom fields of services must conform to.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "JSON Schema",
+                        "name": "schema",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.CustomFieldSchemaOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
                     }
                 }
             }
@@ -108,6 +375,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ServiceOutput"
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
                     }
                 }
             }
@@ -132,6 +405,12 @@ const docTemplate = `{
                         "description": "Return related versions",
                         "name": "versions",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously fetched representation",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -140,12 +419,21 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.GetServiceWithVersionsOutput"
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
                     }
                 }
             },
             "patch": {
+                "description": "With 'application/json', only the fields present in the body are updated.\n'application/merge-patch+json' (RFC 7396) and 'application/json-patch+json' (RFC 6902) are applied\nto the service's name, description, owner and tags; a null in a merge patch clears the field.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
                 ],
                 "produces": [
                     "application/json"
@@ -159,6 +447,12 @@ const docTemplate = `{
                         "in": "header",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag of the service being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
                     {
                         "description": "Service update JSON",
                         "name": "version",
@@ -170,11 +464,48 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/api.ServiceOutput"
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/service/{id}/renames": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the rename history of a service.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListServiceRenamesOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
                     }
                 }
             }
@@ -212,6 +543,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.CreateVersionOutput"
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
                     }
                 }
             }
@@ -259,86 +596,1745 @@ const docTemplate = `{
                         "description": "Search records by name",
                         "name": "name",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/api.ListServicesOutput"
+                    },
+                    {
+                        "enum": [
+                            "experimental",
+                            "production",
+                            "deprecated"
+                        ],
+                        "type": "string",
+                        "description": "Filter by lifecycle",
+                        "name": "lifecycle",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 4,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Filter by criticality tier, 0 for unclassified",
+                        "name": "tier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by repository URL",
+                        "name": "repositoryURL",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "runbook",
+                            "dashboard",
+                            "docs"
+                        ],
+                        "type": "string",
+                        "description": "Only return services with this link",
+                        "name": "hasLink",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return services with a contact of this type",
+                        "name": "contactType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return services with a contact with this value",
+                        "name": "contact",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only return services whose custom fields match, e.g. 'team:payments'",
+                        "name": "customField",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListServicesOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/by-slug/{slug}": {
+            "get": {
+                "description": "Slugs never change, even if the service is renamed.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a service by its slug.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return related versions",
+                        "name": "versions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously fetched representation",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.GetServiceWithVersionsOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/export": {
+            "get": {
+                "description": "The response is streamed. If 'versions' is true, a row is written for every version of a service.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "summary": "Export services as CSV or newline delimited JSON.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Write a row for every version",
+                        "name": "versions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit results",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Query offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key to sort records by",
+                        "name": "sortKey",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Sort records in descending order",
+                        "name": "descending",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search records by name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "experimental",
+                            "production",
+                            "deprecated"
+                        ],
+                        "type": "string",
+                        "description": "Filter by lifecycle",
+                        "name": "lifecycle",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 4,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Filter by criticality tier, 0 for unclassified",
+                        "name": "tier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by repository URL",
+                        "name": "repositoryURL",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "runbook",
+                            "dashboard",
+                            "docs"
+                        ],
+                        "type": "string",
+                        "description": "Only return services with this link",
+                        "name": "hasLink",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return services with a contact of this type",
+                        "name": "contactType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return services with a contact with this value",
+                        "name": "contact",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only return services whose custom fields match, e.g. 'team:payments'",
+                        "name": "customField",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}/changelog": {
+            "get": {
+                "description": "The changelog follows the Keep a Changelog format and lists versions from newest to oldest.\nVersions are ordered by semantic versioning if all of them are semantic versions, and by creation time otherwise.",
+                "produces": [
+                    "text/markdown"
+                ],
+                "summary": "Get the changelog of a service as markdown.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Oldest version to include",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Newest version to include",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}/deployments": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the deployments of a service",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only list deployments to this environment",
+                        "name": "environment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit results",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Query offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListDeploymentsOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     }
                 }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Record a deployment of a version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Deployment JSON",
+                        "name": "deployment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateDeploymentInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.DeploymentOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}/maintainers": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the maintainers of a service",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListMaintainersOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replace the maintainers of a service",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Maintainers JSON",
+                        "name": "maintainers",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetMaintainersInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListMaintainersOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}/versions/{version}/approvals": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Approve the promotion of a version to the next stage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Approval JSON",
+                        "name": "approval",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApprovePromotionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PromotionStateOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}/versions/{version}/compatibility": {
+            "get": {
+                "description": "The report lists the changes between the OpenAPI spec of the version and that of the previous version with an OpenAPI spec.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the compatibility report of a version.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CompatibilityReportOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}/versions/{version}/promote": {
+            "post": {
+                "description": "Promotions are rejected with a 409 listing the reasons unless the version soaked long enough in its current stage and enough maintainers approved the promotion.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Promote a version to the next stage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Promotion JSON",
+                        "name": "promotion",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoteVersionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PromotionStateOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}/versions/{version}/promotion": {
+            "get": {
+                "description": "Returns the stages the version was promoted to and what blocks its promotion to the next stage.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the promotion state of a version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PromotionStateOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}/versions/{version}/spec": {
+            "get": {
+                "description": "The spec is returned as it was uploaded, with a content type matching its format and encoding.",
+                "produces": [
+                    "application/vnd.oai.openapi+json",
+                    "application/vnd.oai.openapi",
+                    "application/json",
+                    "application/yaml",
+                    "text/x-protobuf"
+                ],
+                "summary": "Get the API specification of a version.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously fetched spec",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "The body must be an OpenAPI 2/3 or AsyncAPI document in JSON or YAML, or a protobuf definition.\nThe format is detected from the content and the spec is validated before being stored.\nOpenAPI specs are compared with the spec of the previous version and the result is returned as compatibility.",
+                "consumes": [
+                    "application/json",
+                    "application/yaml",
+                    "text/x-protobuf"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Attach an API specification to a version.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "openapi",
+                            "asyncapi",
+                            "protobuf"
+                        ],
+                        "type": "string",
+                        "description": "Expected format of the spec",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.VersionSpecOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "summary": "Remove the API specification from a version.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services:batch": {
+            "post": {
+                "description": "In 'atomic' mode (default) all operations are executed in a single transaction and nothing is\nsaved if any operation fails; the response status is then that of the failed operation.\nIn 'bestEffort' mode every operation is executed independently and the response status is 200.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create and update services and versions in a batch.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Batch JSON",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BatchInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.BatchOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/versions": {
+            "get": {
+                "description": "Searches the versions of all services in the catalog, not only the user's own. Exactly one of 'commit' and 'imageDigest' is required.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Look up versions by commit or container image digest.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Git commit SHA, or a prefix of at least 7 characters",
+                        "name": "commit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Container image digest, e.g. sha256:\u003chex\u003e",
+                        "name": "imageDigest",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.FindVersionsOutput"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.BatchInput": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "mode": {
+                    "description": "Mode is either 'atomic' (default) or 'bestEffort'.",
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "bestEffort"
+                    ]
+                },
+                "operations": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api.BatchOperationInput"
+                    }
+                }
+            }
+        },
+        "api.BatchItemOutput": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "data": {},
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.BatchOperationInput": {
+            "type": "object",
+            "properties": {
+                "ifMatch": {
+                    "description": "IfMatch has the same semantics as the If-Match header of UpdateService.",
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "createVersion"
+                    ]
+                },
+                "service": {
+                    "type": "object"
+                },
+                "serviceID": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "object"
+                }
+            }
+        },
+        "api.BatchOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BatchItemOutput"
+                    }
+                }
+            }
+        },
+        "api.CheckResult": {
+            "type": "object",
+            "properties": {
+                "details": {},
+                "duration": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CompatibilityReportOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.CompatibilityReport"
+                }
+            }
+        },
+        "api.CreateVersionOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Version"
+                }
+            }
+        },
+        "api.CustomFieldSchemaOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.CustomFieldSchema"
+                }
+            }
+        },
+        "api.DeploymentOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Deployment"
+                }
+            }
+        },
+        "api.FindVersionsOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ServiceVersion"
+                    }
+                }
+            }
+        },
+        "api.GetServiceWithVersionsOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/api.ServiceWithVersions"
+                }
+            }
+        },
+        "api.HealthOutput": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/api.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ImportOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportServiceResult"
+                    }
+                },
+                "dryRun": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.ListCurrentDeploymentsOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CurrentDeployment"
+                    }
+                }
+            }
+        },
+        "api.ListDeploymentsOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Deployment"
+                    }
+                }
+            }
+        },
+        "api.ListEnvironmentsOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.ListMaintainersOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Maintainer"
+                    }
+                }
+            }
+        },
+        "api.ListServiceRenamesOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ServiceRename"
+                    }
+                }
+            }
+        },
+        "api.ListServicesOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Service"
+                    }
+                }
+            }
+        },
+        "api.LoginOutput": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.PromotionStateOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.PromotionState"
+                }
+            }
+        },
+        "api.RegisterOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "api.ServiceOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Service"
+                }
+            }
+        },
+        "api.ServiceWithVersions": {
+            "type": "object",
+            "properties": {
+                "contacts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Contact"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "customFields": {
+                    "description": "CustomFields must conform to the latest CustomFieldSchema.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CustomFields"
+                        }
+                    ]
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lifecycle": {
+                    "description": "Lifecycle is one of experimental, production or deprecated.",
+                    "type": "string"
+                },
+                "links": {
+                    "$ref": "#/definitions/models.ServiceLinks"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the team or person responsible for this service.",
+                    "type": "string"
+                },
+                "repositoryURL": {
+                    "type": "string"
+                },
+                "slug": {
+                    "description": "Slug is a DNS label derived from the name on creation, used to refer to the\nservice in URLs. It never changes, even if the service is renamed.",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tier": {
+                    "description": "Tier is the criticality of the service, from 1 (most critical) to 4. 0 means unclassified.",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Version"
+                    }
+                }
+            }
+        },
+        "api.UserAuthInput": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "api.VersionSpecOutput": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.VersionSpec"
+                }
+            }
+        },
+        "middleware.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "requestID": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApprovePromotionInput": {
+            "type": "object",
+            "properties": {
+                "stage": {
+                    "description": "Stage defaults to the next stage of the Version.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Artifact": {
+            "type": "object",
+            "required": [
+                "name",
+                "url"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "models.ChangelogEntry": {
+            "type": "object",
+            "required": [
+                "description",
+                "type"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "links": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "added",
+                        "changed",
+                        "removed",
+                        "fixed",
+                        "security"
+                    ]
+                }
+            }
+        },
+        "models.CompatibilityReport": {
+            "type": "object",
+            "properties": {
+                "baseVersion": {
+                    "type": "string"
+                },
+                "baseVersionID": {
+                    "type": "integer"
+                },
+                "breaking": {
+                    "type": "boolean"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/spec.Change"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "versionID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Contact": {
+            "type": "object",
+            "required": [
+                "type",
+                "value"
+            ],
+            "properties": {
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "slack",
+                        "pagerduty",
+                        "opsgenie",
+                        "msteams",
+                        "phone",
+                        "url"
+                    ]
+                },
+                "value": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "models.CreateDeploymentInput": {
+            "type": "object",
+            "required": [
+                "environment",
+                "version"
+            ],
+            "properties": {
+                "deployedAt": {
+                    "description": "DeployedAt defaults to the current time.",
+                    "type": "string"
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string",
+                    "maxLength": 50
+                }
+            }
+        },
+        "models.CreateServiceInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "contacts": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/models.Contact"
+                    }
+                },
+                "customFields": {
+                    "$ref": "#/definitions/models.CustomFields"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "lifecycle": {
+                    "description": "Lifecycle defaults to production.",
+                    "type": "string",
+                    "enum": [
+                        "experimental",
+                        "production",
+                        "deprecated"
+                    ]
+                },
+                "links": {
+                    "$ref": "#/definitions/models.ServiceLinks"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "repositoryURL": {
+                    "type": "string",
+                    "maxLength": 2048
+                },
+                "slug": {
+                    "description": "Slug is derived from the name if it's empty.",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tier": {
+                    "type": "integer",
+                    "maximum": 4,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.CreateVersionInput": {
+            "type": "object",
+            "required": [
+                "version"
+            ],
+            "properties": {
+                "changelog": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/models.ChangelogEntry"
+                    }
+                },
+                "release": {
+                    "$ref": "#/definitions/models.ReleaseMetadata"
+                },
+                "spec": {
+                    "description": "Spec is an optional API specification to attach to the Version, see PutVersionSpec.",
+                    "type": "string"
+                },
+                "specFormat": {
+                    "type": "string",
+                    "enum": [
+                        "openapi",
+                        "asyncapi",
+                        "protobuf"
+                    ]
+                },
+                "version": {
+                    "type": "string",
+                    "maxLength": 50
+                }
+            }
+        },
+        "models.CurrentDeployment": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deployedAt": {
+                    "type": "string"
+                },
+                "deployedBy": {
+                    "type": "integer"
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "requestID": {
+                    "type": "string"
+                },
+                "serviceID": {
+                    "type": "integer"
+                },
+                "serviceName": {
+                    "type": "string"
+                },
+                "serviceSlug": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                },
+                "versionID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CustomFieldSchema": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "schema": {
+                    "type": "object"
+                }
+            }
+        },
+        "models.CustomFields": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "models.Deployment": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deployedAt": {
+                    "type": "string"
+                },
+                "deployedBy": {
+                    "type": "integer"
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "requestID": {
+                    "type": "string"
+                },
+                "serviceID": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "string"
+                },
+                "versionID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportServiceResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "addedVersions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "serviceID": {
+                    "type": "integer"
+                }
             }
-        }
-    },
-    "definitions": {
-        "api.CreateVersionOutput": {
+        },
+        "models.Maintainer": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/models.Version"
+                "createdAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "api.GetServiceWithVersionsOutput": {
+        "models.PromoteVersionInput": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/api.ServiceWithVersions"
+                "stage": {
+                    "description": "Stage defaults to the next stage of the Version.",
+                    "type": "string"
                 }
             }
         },
-        "api.ListServicesOutput": {
+        "models.Promotion": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Service"
-                    }
+                "promotedAt": {
+                    "type": "string"
+                },
+                "promotedBy": {
+                    "type": "integer"
+                },
+                "requestID": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string"
                 }
             }
         },
-        "api.LoginOutput": {
+        "models.PromotionApproval": {
             "type": "object",
             "properties": {
-                "accessToken": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "requestID": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "api.RegisterOutput": {
+        "models.PromotionState": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/models.User"
+                "approvals": {
+                    "description": "Approvals are the approvals for the promotion to NextStage.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PromotionApproval"
+                    }
+                },
+                "blockers": {
+                    "description": "Blockers are the reasons why the Version can't be promoted to NextStage right now.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "nextStage": {
+                    "description": "NextStage is empty once the Version reached the last stage.",
+                    "type": "string"
+                },
+                "promotions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Promotion"
+                    }
+                },
+                "requiredApprovals": {
+                    "type": "integer"
+                },
+                "stage": {
+                    "description": "Stage is the latest stage the Version was promoted to, or empty.",
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
                 }
             }
         },
-        "api.ServiceOutput": {
+        "models.ReleaseMetadata": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/models.Service"
+                "artifacts": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/models.Artifact"
+                    }
+                },
+                "buildTime": {
+                    "type": "string"
+                },
+                "commit": {
+                    "description": "Commit is the full SHA of the git commit the Version was built from.",
+                    "type": "string"
+                },
+                "imageDigest": {
+                    "description": "ImageDigest is the digest of the container image, e.g. 'sha256:\u003chex\u003e'.",
+                    "type": "string"
                 }
             }
         },
-        "api.ServiceWithVersions": {
+        "models.Service": {
             "type": "object",
             "properties": {
+                "contacts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Contact"
+                    }
+                },
                 "createdAt": {
                     "type": "string"
                 },
+                "customFields": {
+                    "description": "CustomFields must conform to the latest CustomFieldSchema.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CustomFields"
+                        }
+                    ]
+                },
                 "description": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "lifecycle": {
+                    "description": "Lifecycle is one of experimental, production or deprecated.",
+                    "type": "string"
+                },
+                "links": {
+                    "$ref": "#/definitions/models.ServiceLinks"
+                },
                 "name": {
                     "type": "string"
                 },
+                "owner": {
+                    "description": "Owner is the team or person responsible for this service.",
+                    "type": "string"
+                },
+                "repositoryURL": {
+                    "type": "string"
+                },
+                "slug": {
+                    "description": "Slug is a DNS label derived from the name on creation, used to refer to the\nservice in URLs. It never changes, even if the service is renamed.",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tier": {
+                    "description": "Tier is the criticality of the service, from 1 (most critical) to 4. 0 means unclassified.",
+                    "type": "integer"
+                },
                 "updatedAt": {
                     "type": "string"
                 },
@@ -346,93 +2342,106 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "versions": {
+                    "description": "Versions contains the different versions of this service.\nIt helps us fetch the versions without a JOIN query.",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Version"
+                        "type": "string"
                     }
                 }
             }
         },
-        "api.UserAuthInput": {
+        "models.ServiceLinks": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "password": {
+                "dashboard": {
                     "type": "string",
-                    "maxLength": 20
+                    "maxLength": 2048
                 },
-                "username": {
+                "docs": {
                     "type": "string",
-                    "maxLength": 20
+                    "maxLength": 2048
+                },
+                "runbook": {
+                    "type": "string",
+                    "maxLength": 2048
                 }
             }
         },
-        "models.CreateServiceInput": {
+        "models.ServiceRename": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "description": {
+                "createdAt": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 50
+                "id": {
+                    "type": "integer"
                 },
-                "userID": {
+                "newName": {
+                    "type": "string"
+                },
+                "oldName": {
+                    "type": "string"
+                },
+                "requestID": {
+                    "type": "string"
+                },
+                "serviceID": {
                     "type": "integer"
                 }
             }
         },
-        "models.CreateVersionInput": {
+        "models.ServiceVersion": {
             "type": "object",
-            "required": [
-                "version"
-            ],
             "properties": {
                 "changelog": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChangelogEntry"
+                    }
+                },
+                "createdAt": {
                     "type": "string"
                 },
-                "serviceID": {
+                "id": {
                     "type": "integer"
                 },
-                "userID": {
+                "release": {
+                    "$ref": "#/definitions/models.ReleaseMetadata"
+                },
+                "serviceID": {
                     "type": "integer"
                 },
-                "version": {
-                    "type": "string",
-                    "maxLength": 50
-                }
-            }
-        },
-        "models.Service": {
-            "type": "object",
-            "properties": {
-                "createdAt": {
+                "serviceName": {
                     "type": "string"
                 },
-                "description": {
+                "serviceSlug": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
+                "spec": {
+                    "description": "Spec is only set right after the Version is created with an API specification.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.VersionSpec"
+                        }
+                    ]
                 },
                 "updatedAt": {
                     "type": "string"
                 },
-                "userID": {
-                    "type": "integer"
-                },
-                "versions": {
-                    "description": "Versions contains the different versions of this service.\nIt helps us fetch the versions without a JOIN query.",
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SetMaintainersInput": {
+            "type": "object",
+            "required": [
+                "usernames"
+            ],
+            "properties": {
+                "usernames": {
                     "type": "array",
+                    "maxItems": 50,
                     "items": {
                         "type": "string"
                     }
@@ -442,12 +2451,63 @@ const docTemplate = `{
         "models.UpdateServiceInput": {
             "type": "object",
             "properties": {
+                "contacts": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/models.Contact"
+                    }
+                },
+                "customFields": {
+                    "description": "CustomFields replaces all custom fields of the Service.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CustomFields"
+                        }
+                    ]
+                },
                 "description": {
                     "type": "string"
                 },
+                "lifecycle": {
+                    "type": "string",
+                    "enum": [
+                        "experimental",
+                        "production",
+                        "deprecated"
+                    ]
+                },
+                "links": {
+                    "description": "Links replaces all links of the Service.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ServiceLinks"
+                        }
+                    ]
+                },
                 "name": {
                     "type": "string",
-                    "maxLength": 50
+                    "minLength": 1
+                },
+                "owner": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "repositoryURL": {
+                    "description": "RepositoryURL can be cleared with an empty string.",
+                    "type": "string",
+                    "maxLength": 2048
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tier": {
+                    "type": "integer",
+                    "maximum": 4,
+                    "minimum": 0
                 }
             }
         },
@@ -460,6 +2520,10 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "isAdmin": {
+                    "description": "IsAdmin allows the user to manage catalog wide settings, like the custom field schema.",
+                    "type": "boolean"
+                },
                 "updatedAt": {
                     "type": "string"
                 },
@@ -472,7 +2536,10 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "changelog": {
-                    "type": "string"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChangelogEntry"
+                    }
                 },
                 "createdAt": {
                     "type": "string"
@@ -480,9 +2547,20 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "release": {
+                    "$ref": "#/definitions/models.ReleaseMetadata"
+                },
                 "serviceID": {
                     "type": "integer"
                 },
+                "spec": {
+                    "description": "Spec is only set right after the Version is created with an API specification.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.VersionSpec"
+                        }
+                    ]
+                },
                 "updatedAt": {
                     "type": "string"
                 },
@@ -490,6 +2568,68 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.VersionSpec": {
+            "type": "object",
+            "properties": {
+                "compatibility": {
+                    "description": "Compatibility is only set right after an OpenAPI spec is attached.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CompatibilityReport"
+                        }
+                    ]
+                },
+                "contentType": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "encoding": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "versionID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "spec.Change": {
+            "type": "object",
+            "properties": {
+                "breaking": {
+                    "type": "boolean"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "location": {
+                    "description": "Location points to the changed element within the operation, e.g.\n'query.limit' or 'response.200.application/json.name'.",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "operation": {
+                    "description": "Operation is the affected operation, e.g. 'GET /pets/{id}'.",
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 
@@ -11,6 +10,10 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// errInvalidPassword is returned if the password provided while logging in doesn't
+// match the user's password.
+var errInvalidPassword = &models.Error{Kind: models.ErrorKindUnauthenticated, Code: "invalid_password", Message: "invalid password"}
+
 // UserAuthInput represents the user authentication credentials.
 type UserAuthInput struct {
 	Username string `json:"username" binding:"required,max=20"`
@@ -33,23 +36,20 @@ type LoginOutput struct {
 // @Produce json
 // @Param   creds body     UserAuthInput  true  "Auth creds JSON"
 // @Success 201  {object}  RegisterOutput
+// @Failure default  {object}  middleware.Problem
 // @Router  /auth/register [post]
 //
 // Register registers a new user.
 func Register(c *gin.Context) {
 	var input UserAuthInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("invalid registration input: %s", err.Error())})
+		c.Error(invalidInput("invalid registration input", err))
 		return
 	}
 
-	user, err := models.CreateUser(input.Username, input.Password)
+	user, err := models.CreateUser(c.Request.Context(), input.Username, input.Password)
 	if err != nil {
-		if errors.Is(err, models.ErrUniqueConstraintViolation) {
-			c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("unable to create user: %s", err.Error())})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": fmt.Sprintf("unable to create user: %s", err.Error())})
-		}
+		c.Error(fmt.Errorf("unable to create user: %w", err))
 		return
 	}
 
@@ -64,33 +64,30 @@ func Register(c *gin.Context) {
 // @Produce json
 // @Param   creds body     UserAuthInput  true  "Auth creds JSON"
 // @Success 200  {object}  LoginOutput
+// @Failure default  {object}  middleware.Problem
 // @Router  /auth/login [post]
 //
 // Login returns an access token for the user, if found.
 func Login(c *gin.Context) {
 	var input UserAuthInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("invalid login input: %s", err.Error())})
+		c.Error(invalidInput("invalid login input", err))
 		return
 	}
 
-	user, err := models.GetUserByUsername(input.Username)
+	user, err := models.GetUserByUsername(c.Request.Context(), input.Username)
 	if err != nil {
-		if errors.Is(err, models.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("unable to fetch user: %s", err.Error())})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": fmt.Sprintf("unable to fetch user: %s", err.Error())})
-		}
+		c.Error(fmt.Errorf("unable to fetch user: %w", err))
 		return
 	}
 
 	if !verifyPassword(user.Password, input.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid password"})
+		c.Error(errInvalidPassword)
 		return
 	}
 	token, err := auth.GenerateToken(user.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": fmt.Sprintf("unable to create JWT: %s", err.Error())})
+		c.Error(fmt.Errorf("unable to create JWT: %w", err))
 		return
 	}
 
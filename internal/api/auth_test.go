@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/aryan9600/service-catalog/internal/auth"
+	"github.com/aryan9600/service-catalog/internal/middleware"
 	"github.com/aryan9600/service-catalog/internal/models"
 	"github.com/stretchr/testify/assert"
 )
@@ -44,7 +45,7 @@ func TestRegister(t *testing.T) {
 				Password: "secret",
 			},
 			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
-				assert.Equal(t, 400, w.Code)
+				assert.Equal(t, 409, w.Code)
 				assert.Contains(t, string(w.Body.Bytes()), models.ErrUniqueConstraintViolation.Error())
 			},
 		},
@@ -56,7 +57,18 @@ func TestRegister(t *testing.T) {
 			},
 			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
 				assert.Equal(t, 400, w.Code)
-				assert.Contains(t, string(w.Body.Bytes()), "invalid registration input")
+				assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+				var problem middleware.Problem
+				err := json.Unmarshal(w.Body.Bytes(), &problem)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Equal(t, 400, problem.Status)
+				assert.Equal(t, "invalid_input", problem.Code)
+				assert.Equal(t, "/auth/register", problem.Instance)
+				assert.Contains(t, problem.Detail, "invalid registration input")
+				assert.Equal(t, []models.FieldError{{Field: "username", Message: "must be at most 20 characters long"}}, problem.Errors)
 			},
 		},
 	}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	batchOpCreate        = "create"
+	batchOpUpdate        = "update"
+	batchOpCreateVersion = "createVersion"
+
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "bestEffort"
+)
+
+// BatchInput represents the input required to execute a batch of operations.
+type BatchInput struct {
+	// Mode is either 'atomic' (default) or 'bestEffort'.
+	Mode       string                `json:"mode" binding:"omitempty,oneof=atomic bestEffort"`
+	Operations []BatchOperationInput `json:"operations" binding:"required,min=1,max=500"`
+}
+
+// BatchOperationInput represents a single operation of a batch.
+// 'service' must be a CreateServiceInput for 'create' and an UpdateServiceInput for 'update'.
+// 'version' must be a CreateVersionInput for 'createVersion'.
+type BatchOperationInput struct {
+	Op        string          `json:"op" enums:"create,update,createVersion"`
+	ServiceID uint            `json:"serviceID"`
+	Service   json.RawMessage `json:"service" swaggertype:"object"`
+	Version   json.RawMessage `json:"version" swaggertype:"object"`
+	// IfMatch has the same semantics as the If-Match header of UpdateService.
+	IfMatch string `json:"ifMatch"`
+}
+
+// BatchOutput represents the output returned after executing a batch of operations.
+type BatchOutput struct {
+	Data []BatchItemOutput `json:"data"`
+}
+
+// BatchItemOutput represents the result of a single operation of a batch. Status
+// is the HTTP status code the operation would have had as a standalone request.
+type BatchItemOutput struct {
+	Index  int         `json:"index"`
+	Op     string      `json:"op"`
+	Status int         `json:"status"`
+	Data   interface{} `json:"data,omitempty"`
+	Code   string      `json:"code,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// serviceMethods returns a handler which dispatches requests for custom methods
+// on the services collection, i.e. `/services:<method>`, to the provided handlers.
+// Gin treats the colon as the start of a path parameter, so the handler must be
+// registered as `/services:method`; the parameter's value then includes the colon.
+func serviceMethods(handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method, ok := strings.CutPrefix(c.Param("method"), ":")
+		handler, found := handlers[method]
+		if !ok || !found {
+			c.Error(errNotFound)
+			return
+		}
+		handler(c)
+	}
+}
+
+// BatchServices godoc
+// @Summary     Create and update services and versions in a batch.
+// @Description In 'atomic' mode (default) all operations are executed in a single transaction and nothing is
+// @Description saved if any operation fails; the response status is then that of the failed operation.
+// @Description In 'bestEffort' mode every operation is executed independently and the response status is 200.
+// @Accept      json
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Param       batch body   BatchInput true  "Batch JSON"
+// @Success     200  {object}  BatchOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /services:batch [post]
+//
+// BatchServices executes a list of create, update and createVersion operations
+// for the authenticated user, returning the result of each operation by its index.
+func BatchServices(c *gin.Context) {
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	var input BatchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput("invalid batch input", err))
+		return
+	}
+	atomic := input.Mode != batchModeBestEffort
+
+	output := make([]BatchItemOutput, len(input.Operations))
+	var ops []models.BatchOperation
+	// indexes maps the position of an operation in ops to its position in the input.
+	var indexes []int
+	invalid := false
+	for i, opInput := range input.Operations {
+		output[i] = BatchItemOutput{Index: i, Op: opInput.Op}
+		op, err := parseBatchOperation(opInput)
+		if err != nil {
+			problem := middleware.ProblemFromError(invalidInput("invalid operation input", err))
+			output[i].Status = problem.Status
+			output[i].Code = problem.Code
+			output[i].Error = problem.Detail
+			invalid = true
+			continue
+		}
+		ops = append(ops, *op)
+		indexes = append(indexes, i)
+	}
+
+	if invalid && atomic {
+		for i := range output {
+			if output[i].Status == 0 {
+				output[i].Status, output[i].Code, output[i].Error = batchError(models.ErrBatchAborted)
+			}
+		}
+		c.JSON(http.StatusBadRequest, BatchOutput{Data: output})
+		return
+	}
+
+	results, err := models.ExecuteBatch(c.Request.Context(), ops, userID, atomic)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to execute batch: %w", err))
+		return
+	}
+
+	status := http.StatusOK
+	for i, result := range results {
+		item := &output[indexes[i]]
+		if result.Err != nil {
+			item.Status, item.Code, item.Error = batchError(result.Err)
+			if atomic && !errors.Is(result.Err, models.ErrBatchAborted) {
+				status = item.Status
+			}
+			continue
+		}
+		switch item.Op {
+		case batchOpCreate:
+			item.Status = http.StatusCreated
+			item.Data = result.Service
+		case batchOpUpdate:
+			item.Status = http.StatusOK
+			item.Data = result.Service
+		case batchOpCreateVersion:
+			item.Status = http.StatusCreated
+			item.Data = result.Version
+		}
+	}
+
+	c.JSON(status, BatchOutput{Data: output})
+}
+
+// parseBatchOperation decodes and validates the payload of a batch operation.
+func parseBatchOperation(input BatchOperationInput) (*models.BatchOperation, error) {
+	op := &models.BatchOperation{ServiceID: input.ServiceID}
+	switch input.Op {
+	case batchOpCreate:
+		op.CreateService = &models.CreateServiceInput{}
+		if err := decodeBatchPayload(input.Service, op.CreateService); err != nil {
+			return nil, err
+		}
+	case batchOpUpdate:
+		op.UpdateService = &models.UpdateServiceInput{}
+		if err := decodeBatchPayload(input.Service, op.UpdateService); err != nil {
+			return nil, err
+		}
+		if op.UpdateService.IsEmpty() {
+			return nil, fmt.Errorf("empty service update input")
+		}
+		if input.IfMatch != "" {
+			rowVersions, wildcard := parseETags(input.IfMatch, false)
+			if !wildcard {
+				if len(rowVersions) == 0 {
+					return nil, fmt.Errorf("invalid ifMatch: %s", input.IfMatch)
+				}
+				op.UpdateService.ExpectedRowVersions = rowVersions
+			}
+		}
+	case batchOpCreateVersion:
+		op.CreateVersion = &models.CreateVersionInput{}
+		if err := decodeBatchPayload(input.Version, op.CreateVersion); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown op: %q", input.Op)
+	}
+
+	if input.Op != batchOpCreate && input.ServiceID == 0 {
+		return nil, fmt.Errorf("serviceID is required for op %q", input.Op)
+	}
+	return op, nil
+}
+
+func decodeBatchPayload(payload json.RawMessage, obj interface{}) error {
+	if len(payload) == 0 {
+		return fmt.Errorf("missing payload")
+	}
+	if err := json.Unmarshal(payload, obj); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// batchError returns the HTTP status code, error code and message reported for the
+// error of a batch operation.
+func batchError(err error) (int, string, string) {
+	if errors.Is(err, models.ErrBatchAborted) {
+		return http.StatusFailedDependency, "batch_aborted", err.Error()
+	}
+	problem := middleware.ProblemFromError(err)
+	return problem.Status, problem.Code, problem.Detail
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchServices(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		auth       bool
+		userID     uint
+		assertFunc func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "executing an atomic batch",
+			body: `{"operations": [
+				{"op": "create", "service": {"name": "queue", "description": "message queue"}},
+				{"op": "createVersion", "serviceID": 4, "version": {"version": "v3"}}
+			]}`,
+			auth:   true,
+			userID: uint(2),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, w.Code)
+
+				var response BatchOutput
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Len(t, response.Data, 2)
+				assert.Equal(t, 201, response.Data[0].Status)
+				assert.Equal(t, 201, response.Data[1].Status)
+
+				svc, err := models.GetService(context.Background(), 4, 2)
+				assert.NoError(t, err)
+				assert.Contains(t, svc.Versions, "v3")
+			},
+		},
+		{
+			name: "executing an atomic batch with a failing operation rolls back all operations",
+			body: `{"mode": "atomic", "operations": [
+				{"op": "create", "service": {"name": "cache"}},
+				{"op": "createVersion", "serviceID": 999, "version": {"version": "1.0"}}
+			]}`,
+			auth:   true,
+			userID: uint(2),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 404, w.Code)
+
+				var response BatchOutput
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Equal(t, 424, response.Data[0].Status)
+				assert.Equal(t, 404, response.Data[1].Status)
+				assert.Equal(t, 1, response.Data[1].Index)
+
+				services, err := models.ListServices(context.Background(), models.ListServicesInput{UserID: 2, Name: "cache"})
+				assert.NoError(t, err)
+				assert.Len(t, services, 0)
+			},
+		},
+		{
+			name: "executing a best effort batch with a failing operation",
+			body: `{"mode": "bestEffort", "operations": [
+				{"op": "create", "service": {"name": "cache"}},
+				{"op": "update", "serviceID": 999, "service": {"description": "nope"}},
+				{"op": "update", "service": {}}
+			]}`,
+			auth:   true,
+			userID: uint(2),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, w.Code)
+
+				var response BatchOutput
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Equal(t, 201, response.Data[0].Status)
+				assert.Equal(t, 404, response.Data[1].Status)
+				assert.Equal(t, 400, response.Data[2].Status)
+
+				services, err := models.ListServices(context.Background(), models.ListServicesInput{UserID: 2, Name: "cache"})
+				assert.NoError(t, err)
+				assert.Len(t, services, 1)
+			},
+		},
+		{
+			name:   "executing an empty batch returns a 400",
+			body:   `{"operations": []}`,
+			auth:   true,
+			userID: uint(2),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 400, w.Code)
+			},
+		},
+		{
+			name: "executing a batch for an unauthenticated user returns a 401",
+			body: `{"operations": [{"op": "create", "service": {"name": "cache"}}]}`,
+			auth: false,
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 401, w.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/services:batch", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			if tt.auth {
+				err = addAuthorizationHeader(tt.userID, req)
+				assert.NoError(t, err)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			tt.assertFunc(t, w)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/aryan9600/service-catalog/internal/backstage"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const yamlContentType = "application/yaml"
+
+// ImportOutput represents the output returned after importing Backstage entities.
+type ImportOutput struct {
+	DryRun bool                         `json:"dryRun"`
+	Data   []models.ImportServiceResult `json:"data"`
+}
+
+// ImportInput represents the query parameters accepted while importing Backstage entities.
+type ImportInput struct {
+	DryRun bool `form:"dryRun"`
+}
+
+// ImportCatalog godoc
+// @Summary     Import services from Backstage catalog-info.yaml entities.
+// @Description The body may contain one or many YAML documents. Only entities of kind 'Component' are imported,
+// @Description others are reported as skipped. Services are matched by name and updated if they already exist.
+// @Accept      application/yaml
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Param       dryRun query bool false "Report the changes without saving them"
+// @Success     200  {object}  ImportOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /import [post]
+//
+// ImportCatalog creates or updates Services for the authenticated user from the
+// Backstage Component entities present in the request body.
+func ImportCatalog(c *gin.Context) {
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	var input ImportInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.Error(invalidInput("invalid query parameters", err))
+		return
+	}
+
+	entities, err := backstage.Decode(c.Request.Body)
+	if err != nil {
+		c.Error(invalidInput("invalid import input", err))
+		return
+	}
+	if len(entities) == 0 {
+		c.Error(models.NewValidationError("invalid import input: no entities found"))
+		return
+	}
+
+	var inputs []models.ImportServiceInput
+	var skipped []models.ImportServiceResult
+	for i, entity := range entities {
+		if entity.Kind != backstage.KindComponent {
+			skipped = append(skipped, models.ImportServiceResult{
+				Name:   entity.Metadata.Name,
+				Action: models.ImportActionSkipped,
+				Reason: fmt.Sprintf("unsupported kind: %s", entity.Kind),
+			})
+			continue
+		}
+		if err := entity.Validate(); err != nil {
+			c.Error(models.NewValidationError(fmt.Sprintf("invalid import input: entity %d: %s", i, err.Error())))
+			return
+		}
+		inputs = append(inputs, entity.ToImportInput())
+	}
+
+	results, err := models.ImportServices(c.Request.Context(), inputs, userID, input.DryRun)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to import services: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, ImportOutput{
+		DryRun: input.DryRun,
+		Data:   append(results, skipped...),
+	})
+}
+
+// ExportCatalog godoc
+// @Summary     Export all services as Backstage catalog-info.yaml entities.
+// @Description Exports the whole catalog, not only the user's own services.
+// @Produce     application/yaml
+// @Param       Authorization header string true "Bearer token"
+// @Success     200  {string}  string
+// @Failure     default  {object}  middleware.Problem
+// @Router      /export [get]
+//
+// ExportCatalog returns all Services in the catalog as a stream of Backstage
+// Component entities, which can be imported back using ImportCatalog.
+func ExportCatalog(c *gin.Context) {
+	services, err := models.ListServices(c.Request.Context(), models.ListServicesInput{
+		SortKey: "name",
+	})
+	if err != nil {
+		c.Error(fmt.Errorf("unable to list services: %w", err))
+		return
+	}
+
+	entities := make([]backstage.Entity, 0, len(services))
+	for _, svc := range services {
+		entities = append(entities, backstage.FromService(svc))
+	}
+
+	var buf bytes.Buffer
+	if err := backstage.Encode(&buf, entities); err != nil {
+		c.Error(fmt.Errorf("unable to export services: %w", err))
+		return
+	}
+	c.Data(http.StatusOK, yamlContentType, buf.Bytes())
+}
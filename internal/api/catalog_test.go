@@ -0,0 +1,244 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aryan9600/service-catalog/internal/backstage"
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportCatalog(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		auth       bool
+		userID     uint
+		setup      func(t *testing.T)
+		assertFunc func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "importing in dry run mode does not create services",
+			path: "/import?dryRun=true",
+			body: `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: billing
+  description: invoices and payments
+spec:
+  type: service
+  owner: team-payments
+`,
+			auth:   true,
+			userID: uint(2),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, w.Code)
+
+				var response ImportOutput
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.True(t, response.DryRun)
+				assert.Len(t, response.Data, 1)
+				assert.Equal(t, models.ImportActionCreated, response.Data[0].Action)
+
+				services, err := models.ListServices(context.Background(), models.ListServicesInput{UserID: 2, Name: "billing"})
+				assert.NoError(t, err)
+				assert.Len(t, services, 0)
+			},
+		},
+		{
+			name: "importing multiple components creates services and versions",
+			path: "/import",
+			body: `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: billing
+  description: invoices and payments
+  tags: [payments, java]
+  annotations:
+    service-catalog/versions: "1.0, 1.1"
+spec:
+  type: service
+  owner: team-payments
+---
+apiVersion: backstage.io/v1alpha1
+kind: API
+metadata:
+  name: billing-api
+`,
+			auth:   true,
+			userID: uint(2),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, w.Code)
+
+				var response ImportOutput
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Len(t, response.Data, 2)
+				assert.Equal(t, models.ImportActionCreated, response.Data[0].Action)
+				assert.Equal(t, []string{"1.0", "1.1"}, response.Data[0].AddedVersions)
+				assert.Equal(t, models.ImportActionSkipped, response.Data[1].Action)
+
+				svc, err := models.GetService(context.Background(), response.Data[0].ServiceID, 2)
+				assert.NoError(t, err)
+				assert.Equal(t, "team-payments", svc.Owner)
+				assert.Equal(t, []string{"payments", "java"}, []string(svc.Tags))
+				assert.Equal(t, []string{"1.0", "1.1"}, []string(svc.Versions))
+			},
+		},
+		{
+			name: "importing an existing component updates the service",
+			path: "/import",
+			body: `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: billing
+  description: invoices, payments and refunds
+  annotations:
+    service-catalog/versions: "1.0,1.1,1.2"
+spec:
+  owner: team-payments
+`,
+			auth:   true,
+			userID: uint(2),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, w.Code)
+
+				var response ImportOutput
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Len(t, response.Data, 1)
+				assert.Equal(t, models.ImportActionUpdated, response.Data[0].Action)
+				assert.Equal(t, []string{"1.2"}, response.Data[0].AddedVersions)
+
+				services, err := models.ListServices(context.Background(), models.ListServicesInput{UserID: 2, Name: "billing"})
+				assert.NoError(t, err)
+				assert.Len(t, services, 1)
+				assert.Equal(t, "invoices, payments and refunds", services[0].Description)
+			},
+		},
+		{
+			name: "importing a component without a name returns a 400",
+			path: "/import",
+			body: `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  description: nameless
+`,
+			auth:   true,
+			userID: uint(2),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 400, w.Code)
+				assert.Contains(t, w.Body.String(), "metadata.name is required")
+			},
+		},
+		{
+			name: "importing a component with a too long owner returns a 400",
+			path: "/import",
+			body: `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: billing
+spec:
+  owner: ` + strings.Repeat("a", 256) + `
+`,
+			auth:   true,
+			userID: uint(2),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 400, w.Code)
+				assert.Contains(t, w.Body.String(), "spec.owner must be at most 255 characters long")
+			},
+		},
+		{
+			name: "importing an existing component with an invalid name returns a 400",
+			path: "/import",
+			body: `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: billing
+spec:
+  owner: team-payments
+`,
+			auth:   true,
+			userID: uint(2),
+			setup: func(t *testing.T) {
+				err := models.SetServiceNamingConfig(config.Services{NameMaxLength: 5})
+				assert.NoError(t, err)
+				t.Cleanup(func() {
+					err := models.SetServiceNamingConfig(config.Services{NameMaxLength: 50})
+					assert.NoError(t, err)
+				})
+			},
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 400, w.Code)
+				assert.Contains(t, w.Body.String(), "invalid service name: must be at most 5 characters long")
+			},
+		},
+		{
+			name: "importing for an unauthenticated user returns a 401",
+			path: "/import",
+			auth: false,
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 401, w.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+			req, err := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/yaml")
+			if tt.auth {
+				err = addAuthorizationHeader(tt.userID, req)
+				assert.NoError(t, err)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			tt.assertFunc(t, w)
+		})
+	}
+}
+
+func TestExportCatalog(t *testing.T) {
+	req, err := http.NewRequest("GET", "/export", nil)
+	assert.NoError(t, err)
+	err = addAuthorizationHeader(uint(1), req)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+
+	entities, err := backstage.Decode(w.Body)
+	assert.NoError(t, err)
+	// The whole catalog is exported, including other users' services.
+	exported := map[string]backstage.Entity{}
+	for _, entity := range entities {
+		exported[entity.Metadata.Name] = entity
+	}
+	assert.Contains(t, exported, "observability")
+	if assert.Contains(t, exported, "auth") {
+		assert.Equal(t, backstage.KindComponent, exported["auth"].Kind)
+		assert.Equal(t, []string{"1.0", "1.1"}, exported["auth"].Versions())
+	}
+}
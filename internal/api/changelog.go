@@ -0,0 +1,65 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const markdownContentType = "text/markdown; charset=utf-8"
+
+// GetChangelog godoc
+// @Summary     Get the changelog of a service as markdown.
+// @Description The changelog follows the Keep a Changelog format and lists versions from newest to oldest.
+// @Description Versions are ordered by semantic versioning if all of them are semantic versions, and by creation time otherwise.
+// @Produce     text/markdown
+// @Param       Authorization header string true "Bearer token"
+// @Param       from query string false "Oldest version to include"
+// @Param       to query string false "Newest version to include"
+// @Success     200  {string}  string
+// @Failure     default  {object}  middleware.Problem
+// @Router      /services/{id}/changelog [get]
+//
+// GetChangelog renders the changelogs of the provided Service's Versions within
+// the requested range as markdown.
+func GetChangelog(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	var input models.ChangelogRange
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.Error(invalidInput("invalid query parameters", err))
+		return
+	}
+
+	svc, versions, err := models.ListChangelogVersions(c.Request.Context(), uint(svcId), userID, input)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to fetch changelog: %w", err))
+		return
+	}
+	var buf bytes.Buffer
+	if err := models.WriteChangelogMarkdown(&buf, svc, versions); err != nil {
+		c.Error(fmt.Errorf("unable to render changelog: %w", err))
+		return
+	}
+	c.Data(http.StatusOK, markdownContentType, buf.Bytes())
+}
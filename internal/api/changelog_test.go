@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangelog(t *testing.T) {
+	w := doRequest(t, "POST", "/services", `{"name": "notifications"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var created ServiceOutput
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	svcPath := fmt.Sprintf("/services/%d", created.Data.ID)
+
+	w = doRequest(t, "POST", svcPath+"/version", `{"version": "1.0.0", "changelog": [{"type": "deprecated", "description": "x"}]}`, 2, nil)
+	assert.Equal(t, 400, w.Code)
+	var problem middleware.Problem
+	err = json.Unmarshal(w.Body.Bytes(), &problem)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.Len(t, problem.Errors, 1) {
+		assert.Equal(t, "changelog[0].type", problem.Errors[0].Field)
+	}
+
+	w = doRequest(t, "POST", svcPath+"/version", `{"version": "1.10.0", "changelog": [
+		{"type": "added", "description": "SMS notifications"},
+		{"type": "security", "description": "Sign webhook payloads", "links": ["https://example.com/advisories/1"]}
+	]}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var version CreateVersionOutput
+	err = json.Unmarshal(w.Body.Bytes(), &version)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Len(t, version.Data.Changelog, 2)
+
+	// Free text changelogs are still accepted.
+	w = doRequest(t, "POST", svcPath+"/version", `{"version": "1.2.0", "changelog": "Retry failed deliveries"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	w = doRequest(t, "POST", svcPath+"/version", `{"version": "2.0.0", "changelog": [{"type": "removed", "description": "Fax notifications"}]}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+
+	w = doRequest(t, "GET", svcPath+"/changelog", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "# Changelog\n"))
+	assert.Contains(t, body, "### Security\n\n- Sign webhook payloads <https://example.com/advisories/1>\n")
+	assert.Contains(t, body, "### Changed\n\n- Retry failed deliveries\n")
+	// Versions are ordered by semantic versioning, not by creation.
+	assert.Less(t, strings.Index(body, "## [2.0.0]"), strings.Index(body, "## [1.10.0]"))
+	assert.Less(t, strings.Index(body, "## [1.10.0]"), strings.Index(body, "## [1.2.0]"))
+
+	w = doRequest(t, "GET", svcPath+"/changelog?from=1.2.0&to=1.10.0", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	body = w.Body.String()
+	assert.Contains(t, body, "## [1.10.0]")
+	assert.Contains(t, body, "## [1.2.0]")
+	assert.NotContains(t, body, "## [2.0.0]")
+
+	w = doRequest(t, "GET", svcPath+"/changelog?from=2.0.0&to=1.2.0", "", 2, nil)
+	assert.Equal(t, 400, w.Code)
+
+	w = doRequest(t, "GET", svcPath+"/changelog?from=0.1.0", "", 2, nil)
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestChangelogPrereleases(t *testing.T) {
+	w := doRequest(t, "POST", "/services", `{"name": "scheduler"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var created ServiceOutput
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	svcPath := fmt.Sprintf("/services/%d", created.Data.ID)
+
+	for _, v := range []string{"1.0.0-rc.10", "1.0.0", "1.0.0-alpha.beta", "1.0.0-rc.2", "1.0.0-alpha", "1.0.0-alpha.1"} {
+		w = doRequest(t, "POST", svcPath+"/version", fmt.Sprintf(`{"version": %q, "changelog": "Release %s"}`, v, v), 2, nil)
+		assert.Equal(t, 201, w.Code)
+	}
+
+	w = doRequest(t, "GET", svcPath+"/changelog", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	body := w.Body.String()
+	// Numeric identifiers of pre-releases are compared numerically, and are lower
+	// than alphanumeric ones.
+	ordered := []string{"1.0.0", "1.0.0-rc.10", "1.0.0-rc.2", "1.0.0-alpha.beta", "1.0.0-alpha.1", "1.0.0-alpha"}
+	for i := 1; i < len(ordered); i++ {
+		assert.Less(t, strings.Index(body, "## ["+ordered[i-1]+"]"), strings.Index(body, "## ["+ordered[i]+"]"))
+	}
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	newEngine := func(cfg config.CORS) *gin.Engine {
+		engine := gin.New()
+		engine.Use(middleware.ErrorHandler(), middleware.CORS(cfg))
+		engine.GET("/services", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return engine
+	}
+	cfg := config.Default().CORS
+	cfg.AllowedOrigins = []string{"https://ui.example.com"}
+	cfg.AllowCredentials = true
+	withCredentials := newEngine(cfg)
+	cfg.AllowedOrigins = []string{"*"}
+	cfg.AllowCredentials = false
+	anyOrigin := newEngine(cfg)
+	disabled := newEngine(config.Default().CORS)
+
+	tests := []struct {
+		name        string
+		engine      *gin.Engine
+		method      string
+		header      http.Header
+		code        int
+		allowOrigin string
+		problemCode string
+	}{
+		{
+			name:        "allowed origin",
+			engine:      withCredentials,
+			method:      "GET",
+			header:      http.Header{"Origin": {"https://ui.example.com"}},
+			code:        200,
+			allowOrigin: "https://ui.example.com",
+		},
+		{
+			name:   "other origin",
+			engine: withCredentials,
+			method: "GET",
+			header: http.Header{"Origin": {"https://evil.example.com"}},
+			code:   200,
+		},
+		{
+			name:        "any origin",
+			engine:      anyOrigin,
+			method:      "GET",
+			header:      http.Header{"Origin": {"https://evil.example.com"}},
+			code:        200,
+			allowOrigin: "*",
+		},
+		{
+			name:   "disabled",
+			engine: disabled,
+			method: "GET",
+			header: http.Header{"Origin": {"https://ui.example.com"}},
+			code:   200,
+		},
+		{
+			name:   "preflight",
+			engine: withCredentials,
+			method: "OPTIONS",
+			header: http.Header{
+				"Origin":                         {"https://ui.example.com"},
+				"Access-Control-Request-Method":  {"PATCH"},
+				"Access-Control-Request-Headers": {"authorization, if-match"},
+			},
+			code:        204,
+			allowOrigin: "https://ui.example.com",
+		},
+		{
+			name:   "preflight of other origin",
+			engine: withCredentials,
+			method: "OPTIONS",
+			header: http.Header{
+				"Origin":                        {"https://evil.example.com"},
+				"Access-Control-Request-Method": {"GET"},
+			},
+			code:        403,
+			problemCode: "cors_rejected",
+		},
+		{
+			name:   "preflight of method which isn't allowed",
+			engine: withCredentials,
+			method: "OPTIONS",
+			header: http.Header{
+				"Origin":                        {"https://ui.example.com"},
+				"Access-Control-Request-Method": {"TRACE"},
+			},
+			code:        403,
+			allowOrigin: "https://ui.example.com",
+			problemCode: "cors_rejected",
+		},
+		{
+			name:   "preflight of header which isn't allowed",
+			engine: withCredentials,
+			method: "OPTIONS",
+			header: http.Header{
+				"Origin":                         {"https://ui.example.com"},
+				"Access-Control-Request-Method":  {"GET"},
+				"Access-Control-Request-Headers": {"X-Debug"},
+			},
+			code:        403,
+			allowOrigin: "https://ui.example.com",
+			problemCode: "cors_rejected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, "/services", nil)
+			assert.NoError(t, err)
+			req.Header = tt.header
+			w := httptest.NewRecorder()
+			tt.engine.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, tt.allowOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+			if tt.problemCode != "" {
+				var problem middleware.Problem
+				err := json.Unmarshal(w.Body.Bytes(), &problem)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Equal(t, tt.problemCode, problem.Code)
+				return
+			}
+			if tt.allowOrigin == "" {
+				return
+			}
+			if tt.method == "OPTIONS" {
+				assert.Equal(t, "GET, POST, PUT, PATCH, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+				assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "If-Match")
+				assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+			} else {
+				assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "X-Request-ID")
+				assert.Contains(t, w.Header().Values("Vary"), "Origin")
+			}
+			if tt.allowOrigin != "*" {
+				assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+			}
+		})
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+		csp    string
+		hsts   string
+	}{
+		{name: "api", path: "/healthz", csp: "default-src 'none'; frame-ancestors 'none'"},
+		{name: "https", path: "/healthz", header: http.Header{"X-Forwarded-Proto": {"https"}}, csp: "default-src 'none'; frame-ancestors 'none'", hsts: "max-age=31536000"},
+		{name: "swagger", path: "/swagger/index.html", csp: config.Default().Security.SwaggerContentSecurityPolicy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The Swagger UI handler routes by the request URI, which only httptest sets.
+			req := httptest.NewRequest("GET", tt.path, nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, 200, w.Code)
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+			assert.Equal(t, tt.csp, w.Header().Get("Content-Security-Policy"))
+			assert.Equal(t, tt.hsts, w.Header().Get("Strict-Transport-Security"))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CustomFieldSchemaOutput represents the output returned when fetching or
+// registering the custom field schema.
+type CustomFieldSchemaOutput struct {
+	Data models.CustomFieldSchema `json:"data"`
+}
+
+// GetCustomFieldSchema godoc
+// @Summary Get the JSON Schema which custom fields of services must conform to.
+// @Produce json
+// @Param   Authorization header string true "Bearer token"
+// @Success 200  {object}  CustomFieldSchemaOutput
+// @Failure default  {object}  middleware.Problem
+// @Router  /schemas/custom-fields [get]
+//
+// GetCustomFieldSchema returns the latest custom field schema.
+func GetCustomFieldSchema(c *gin.Context) {
+	schema, err := models.GetCustomFieldSchema(c.Request.Context())
+	if err != nil {
+		c.Error(fmt.Errorf("unable to fetch custom field schema: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, CustomFieldSchemaOutput{
+		Data: *schema,
+	})
+}
+
+// RegisterCustomFieldSchema godoc
+// @Summary     Register the JSON Schema which custom fields of services must conform to.
+// @Description Only admins can register schemas. The schema is rejected with a 409 if
+// @Description the custom fields of existing services don't conform to it.
+// @Accept      json
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Param       schema body   object true  "JSON Schema"
+// @Success     201  {object}  CustomFieldSchemaOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /schemas/custom-fields [put]
+//
+// RegisterCustomFieldSchema replaces the custom field schema with the one in the
+// request body.
+func RegisterCustomFieldSchema(c *gin.Context) {
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	var schema json.RawMessage
+	if err := c.ShouldBindJSON(&schema); err != nil {
+		c.Error(invalidInput("invalid custom field schema", err))
+		return
+	}
+
+	registered, err := models.RegisterCustomFieldSchema(c.Request.Context(), schema, userID)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to register custom field schema: %w", err))
+		return
+	}
+	c.JSON(http.StatusCreated, CustomFieldSchemaOutput{
+		Data: *registered,
+	})
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomFields(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"team": {"type": "string"},
+			"pci": {"type": "boolean"}
+		},
+		"additionalProperties": false
+	}`
+
+	w := doRequest(t, "POST", "/services", `{"name": "fraud-detection", "customFields": {"team": "risk"}}`, 2, nil)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), models.ErrNoCustomFieldSchema.Code)
+
+	w = doRequest(t, "GET", "/schemas/custom-fields", "", 2, nil)
+	assert.Equal(t, 404, w.Code)
+
+	w = doRequest(t, "PUT", "/schemas/custom-fields", schema, 2, nil)
+	assert.Equal(t, 403, w.Code)
+
+	w = doRequest(t, "PUT", "/schemas/custom-fields", `{"type": "nope"}`, 1, nil)
+	assert.Equal(t, 400, w.Code)
+
+	w = doRequest(t, "PUT", "/schemas/custom-fields", schema, 1, nil)
+	assert.Equal(t, 201, w.Code)
+
+	w = doRequest(t, "GET", "/schemas/custom-fields", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	var output CustomFieldSchemaOutput
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.JSONEq(t, schema, string(output.Data.Schema))
+
+	w = doRequest(t, "POST", "/services", `{"name": "fraud-detection", "customFields": {"team": 1, "region": "eu"}}`, 2, nil)
+	assert.Equal(t, 400, w.Code)
+	var problem middleware.Problem
+	err = json.Unmarshal(w.Body.Bytes(), &problem)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Len(t, problem.Errors, 2)
+
+	w = doRequest(t, "POST", "/services", `{"name": "fraud-detection", "customFields": {"team": "risk", "pci": true}}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+
+	w = doRequest(t, "GET", "/services?customField=pci:true&customField=team:risk", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	var list ListServicesOutput
+	err = json.Unmarshal(w.Body.Bytes(), &list)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.Len(t, list.Data, 1) {
+		assert.Equal(t, "fraud-detection", list.Data[0].Name)
+		assert.Equal(t, models.CustomFields{"team": "risk", "pci": true}, list.Data[0].CustomFields)
+	}
+
+	w = doRequest(t, "GET", "/services?customField=pci", "", 2, nil)
+	assert.Equal(t, 400, w.Code)
+
+	// Existing services without a team don't conform to a schema requiring one.
+	w = doRequest(t, "PUT", "/schemas/custom-fields", `{"type": "object", "required": ["team"]}`, 1, nil)
+	assert.Equal(t, 409, w.Code)
+
+	// The required properties are enforced even if a service has no custom fields at
+	// all. The schema is registered directly, as existing services don't conform to it.
+	required := models.CustomFieldSchema{Schema: json.RawMessage(`{"type": "object", "required": ["team"]}`), CreatedBy: 1}
+	err = models.DB.Table(models.CustomFieldSchemaTableName).Create(&required).Error
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		models.DB.Table(models.CustomFieldSchemaTableName).Delete(&required)
+	})
+
+	w = doRequest(t, "POST", "/services", `{"name": "chargebacks"}`, 2, nil)
+	assert.Equal(t, 400, w.Code)
+	w = doRequest(t, "POST", "/services", `{"name": "chargebacks", "customFields": {}}`, 2, nil)
+	assert.Equal(t, 400, w.Code)
+	w = doRequest(t, "PATCH", fmt.Sprintf("/services/%d", list.Data[0].ID), `{"customFields": {}}`, 2, nil)
+	assert.Equal(t, 400, w.Code)
+	w = doRequest(t, "POST", "/services", `{"name": "chargebacks", "customFields": {"team": "risk"}}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+}
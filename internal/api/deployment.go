@@ -0,0 +1,172 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// DeploymentOutput represents the output returned after recording a Deployment.
+type DeploymentOutput struct {
+	Data models.Deployment `json:"data"`
+}
+
+// ListDeploymentsOutput represents the output returned while listing the Deployments of a Service.
+type ListDeploymentsOutput struct {
+	Data []models.Deployment `json:"data"`
+}
+
+// ListCurrentDeploymentsOutput represents the output returned while listing what is currently deployed.
+type ListCurrentDeploymentsOutput struct {
+	Data []models.CurrentDeployment `json:"data"`
+}
+
+// ListEnvironmentsOutput represents the output returned while listing the environments.
+type ListEnvironmentsOutput struct {
+	Data []string `json:"data"`
+}
+
+// CreateDeployment godoc
+// @Summary Record a deployment of a version
+// @Accept  json
+// @Produce json
+// @Param   Authorization header string true "Bearer token"
+// @Param   deployment body   models.CreateDeploymentInput true  "Deployment JSON"
+// @Success 201  {object}  DeploymentOutput
+// @Failure default  {object}  middleware.Problem
+// @Router  /services/{id}/deployments [post]
+//
+// CreateDeployment records that a Version of the provided Service was deployed
+// to an environment. It's meant to be called by CI/CD pipelines.
+func CreateDeployment(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	var input models.CreateDeploymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput("invalid deployment input", err))
+		return
+	}
+	input.ServiceID = uint(svcId)
+	input.UserID = userID
+
+	deployment, err := models.CreateDeployment(c.Request.Context(), input)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to record deployment: %w", err))
+		return
+	}
+	c.JSON(http.StatusCreated, DeploymentOutput{
+		Data: *deployment,
+	})
+}
+
+// ListDeployments godoc
+// @Summary List the deployments of a service
+// @Produce json
+// @Param   Authorization header string true "Bearer token"
+// @Param   environment query string false "Only list deployments to this environment"
+// @Param   limit query int false "Limit results"
+// @Param   offset query int false "Query offset"
+// @Success 200  {object}  ListDeploymentsOutput
+// @Failure default  {object}  middleware.Problem
+// @Router  /services/{id}/deployments [get]
+//
+// ListDeployments returns the deployment history of the provided Service, newest first.
+func ListDeployments(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	var input models.ListDeploymentsInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.Error(invalidInput("invalid query parameters", err))
+		return
+	}
+	input.ServiceID = uint(svcId)
+	input.UserID = userID
+
+	deployments, err := models.ListDeployments(c.Request.Context(), input)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to list deployments: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, ListDeploymentsOutput{
+		Data: deployments,
+	})
+}
+
+// ListCurrentDeployments godoc
+// @Summary     List what is currently deployed
+// @Description Returns the latest deployment of each service in the catalog to each environment, not only of the user's own services.
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Param       environment query string false "Only list deployments to this environment, e.g. prod"
+// @Success     200  {object}  ListCurrentDeploymentsOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /deployments/current [get]
+//
+// ListCurrentDeployments returns the Versions currently deployed to each environment
+// across all Services in the catalog.
+func ListCurrentDeployments(c *gin.Context) {
+	var input models.ListCurrentDeploymentsInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.Error(invalidInput("invalid query parameters", err))
+		return
+	}
+
+	deployments, err := models.ListCurrentDeployments(c.Request.Context(), input)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to list deployments: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, ListCurrentDeploymentsOutput{
+		Data: deployments,
+	})
+}
+
+// ListEnvironments godoc
+// @Summary List the environments versions can be deployed to
+// @Produce json
+// @Param   Authorization header string true "Bearer token"
+// @Success 200  {object}  ListEnvironmentsOutput
+// @Failure default  {object}  middleware.Problem
+// @Router  /environments [get]
+//
+// ListEnvironments returns the configured environments in order.
+func ListEnvironments(c *gin.Context) {
+	c.JSON(http.StatusOK, ListEnvironmentsOutput{
+		Data: models.Environments(),
+	})
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployments(t *testing.T) {
+	w := doRequest(t, "POST", "/services", `{"name": "gateway"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var created ServiceOutput
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	svcPath := fmt.Sprintf("/services/%d", created.Data.ID)
+	for _, v := range []string{"1.0", "1.1"} {
+		w = doRequest(t, "POST", svcPath+"/version", fmt.Sprintf(`{"version": "%s"}`, v), 2, nil)
+		assert.Equal(t, 201, w.Code)
+	}
+
+	w = doRequest(t, "GET", "/environments", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"data": ["dev", "staging", "prod"]}`, w.Body.String())
+
+	yesterday := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	tomorrow := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		userID uint
+		code   int
+	}{
+		{name: "unknown environment", path: svcPath, body: `{"version": "1.0", "environment": "qa"}`, userID: 2, code: 400},
+		{name: "unknown version", path: svcPath, body: `{"version": "2.0", "environment": "prod"}`, userID: 2, code: 400},
+		{name: "future deployment", path: svcPath, body: fmt.Sprintf(`{"version": "1.0", "environment": "prod", "deployedAt": "%s"}`, tomorrow), userID: 2, code: 400},
+		{name: "other user's service", path: svcPath, body: `{"version": "1.0", "environment": "prod"}`, userID: 1, code: 404},
+		{name: "past deployment", path: svcPath, body: fmt.Sprintf(`{"version": "1.0", "environment": "prod", "deployedAt": "%s"}`, yesterday), userID: 2, code: 201},
+		{name: "dev", path: svcPath, body: `{"version": "1.1", "environment": "dev"}`, userID: 2, code: 201},
+		{name: "prod", path: svcPath, body: `{"version": "1.1", "environment": "prod"}`, userID: 2, code: 201},
+		{name: "another service", path: "/services/5", body: `{"version": "beta", "environment": "prod"}`, userID: 2, code: 201},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(t, "POST", tt.path+"/deployments", tt.body, tt.userID, nil)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+
+	w = doRequest(t, "GET", svcPath+"/deployments?environment=prod", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	var history ListDeploymentsOutput
+	err = json.Unmarshal(w.Body.Bytes(), &history)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.Len(t, history.Data, 2) {
+		assert.Equal(t, "1.1", history.Data[0].Version)
+		assert.Equal(t, "1.0", history.Data[1].Version)
+	}
+
+	w = doRequest(t, "GET", "/deployments/current?environment=prod", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	var current ListCurrentDeploymentsOutput
+	err = json.Unmarshal(w.Body.Bytes(), &current)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.Len(t, current.Data, 2) {
+		assert.Equal(t, "gateway", current.Data[0].ServiceName)
+		assert.Equal(t, "1.1", current.Data[0].Version)
+		assert.Equal(t, "service mesh", current.Data[1].ServiceName)
+		assert.Equal(t, "beta", current.Data[1].Version)
+	}
+
+	w = doRequest(t, "GET", "/deployments/current", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &current)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.Len(t, current.Data, 3) {
+		assert.Equal(t, "dev", current.Data[0].Environment)
+		assert.Equal(t, "prod", current.Data[1].Environment)
+	}
+
+	// Deployments of other users' services are listed as well.
+	w = doRequest(t, "GET", "/deployments/current?environment=prod", "", 1, nil)
+	assert.Equal(t, 200, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &current)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Len(t, current.Data, 2)
+}
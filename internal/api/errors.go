@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// errUserDetails is returned if the authenticated user's ID is missing from the
+// request context, which means the auth middleware wasn't run.
+var errUserDetails = errors.New("unable to fetch user details")
+
+// errNotFound is returned for requests which don't match any route.
+var errNotFound = middleware.NewProblem(http.StatusNotFound, "not_found", "404 page not found")
+
+// useJSONFieldNames makes the validator report fields by their JSON or form name
+// instead of the Go struct field name.
+func useJSONFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
+
+// invalidInput returns a validation error for an error which occurred while binding
+// or validating the request's input. The message is used as the error's prefix.
+func invalidInput(message string, err error) error {
+	return models.NewValidationError(fmt.Sprintf("%s: %s", message, err.Error()), fieldErrors(err)...)
+}
+
+// fieldErrors returns the details of the fields which failed validation, if err
+// was returned by the validator.
+func fieldErrors(err error) []models.FieldError {
+	var fields []models.FieldError
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		for _, fe := range validationErrs {
+			fields = append(fields, models.FieldError{
+				Field:   fieldPath(fe),
+				Message: fieldMessage(fe),
+			})
+		}
+	}
+	return fields
+}
+
+// fieldPath returns the path of the field without the name of the top level struct,
+// e.g. 'tags[0]' instead of 'CreateServiceInput.tags[0]'.
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	isList := fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		if isList {
+			return fmt.Sprintf("must contain at most %s items", fe.Param())
+		}
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "min":
+		if isList {
+			return fmt.Sprintf("must contain at least %s items", fe.Param())
+		}
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "url", "eq=|url":
+		return "must be a valid URL"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serviceETag returns the entity tag of a Service with the provided row version.
+// The representation which includes the Service's versions gets a tag of its own,
+// since its body differs from the plain one for the same row version.
+func serviceETag(rowVersion uint, withVersions bool) string {
+	if withVersions {
+		return fmt.Sprintf(`"%d-v"`, rowVersion)
+	}
+	return fmt.Sprintf(`"%d"`, rowVersion)
+}
+
+// splitETags splits the value of an If-Match or If-None-Match header into a list of
+// entity tags, without the weak indicator. If weak is false, weak tags are ignored.
+// wildcard is true if the header contains the wildcard '*'.
+func splitETags(header string, weak bool) (tags []string, wildcard bool) {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			wildcard = true
+			continue
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, wildcard
+}
+
+// parseETags parses the value of an If-Match or If-None-Match header into a list
+// of row versions. The tags of both representations of a Service identify its row
+// version, see serviceETag. Tags which don't represent a row version are ignored,
+// since they can never match. If weak is false, weak tags are ignored as well.
+// wildcard is true if the header contains the wildcard '*'.
+func parseETags(header string, weak bool) (rowVersions []uint, wildcard bool) {
+	tags, wildcard := splitETags(header, weak)
+	for _, tag := range tags {
+		value, err := strconv.ParseUint(strings.TrimSuffix(strings.Trim(tag, `"`), "-v"), 10, 0)
+		if err != nil {
+			continue
+		}
+		rowVersions = append(rowVersions, uint(value))
+	}
+	return rowVersions, wildcard
+}
+
+// notModified returns true if the request's If-None-Match header matches the
+// provided entity tag, in which case the response shouldn't contain a body.
+// Weak tags match as well, since If-None-Match uses the weak comparison.
+func notModified(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	tags, wildcard := splitETags(header, true)
+	if wildcard {
+		return true
+	}
+	for _, tag := range tags {
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
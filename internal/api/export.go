@@ -0,0 +1,282 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/logging"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+
+	csvContentType    = "text/csv"
+	ndjsonContentType = "application/x-ndjson"
+
+	// exportFlushInterval is the number of rows written before flushing the response.
+	exportFlushInterval = 100
+)
+
+// ExportServicesInput represents the query parameters accepted while exporting Services.
+// It accepts the same filters as ListServices.
+type ExportServicesInput struct {
+	models.ListServicesInput
+	Format   string `form:"format" binding:"omitempty,oneof=csv ndjson"`
+	Versions bool   `form:"versions"`
+}
+
+// ExportServices godoc
+// @Summary     Export services as CSV or newline delimited JSON.
+// @Description The response is streamed. If 'versions' is true, a row is written for every version of a service.
+// @Produce     text/csv
+// @Produce     application/x-ndjson
+// @Param       Authorization header string true "Bearer token"
+// @Param       format query string false "Export format" Enums(csv, ndjson)
+// @Param       versions query bool false "Write a row for every version"
+// @Param       limit query int false "Limit results"
+// @Param       offset query int false "Query offset"
+// @Param       sortKey query string false "Key to sort records by"
+// @Param       descending query bool false "Sort records in descending order"
+// @Param       name query string false "Search records by name"
+// @Param       lifecycle query string false "Filter by lifecycle" Enums(experimental, production, deprecated)
+// @Param       tier query int false "Filter by criticality tier, 0 for unclassified" minimum(0) maximum(4)
+// @Param       repositoryURL query string false "Filter by repository URL"
+// @Param       hasLink query string false "Only return services with this link" Enums(runbook, dashboard, docs)
+// @Param       contactType query string false "Only return services with a contact of this type"
+// @Param       contact query string false "Only return services with a contact with this value"
+// @Param       customField query []string false "Only return services whose custom fields match, e.g. 'team:payments'" collectionFormat(multi)
+// @Success     200  {string}  string
+// @Failure     default  {object}  middleware.Problem
+// @Router      /services/export [get]
+//
+// ExportServices streams the Services of the authenticated user in the requested
+// format (CSV by default), applying the same filters as ListServices. The server's
+// write timeout doesn't apply to exports.
+func ExportServices(c *gin.Context) {
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	var input ExportServicesInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.Error(invalidInput("invalid query parameters", err))
+		return
+	}
+	input.UserID = userID
+	if input.Format == "" {
+		input.Format = exportFormatCSV
+	}
+
+	// Exports of large catalogs may take longer than the server's write timeout allows,
+	// so the deadline is lifted for this response. Clients going away still end it.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logging.FromContext(c.Request.Context()).Warn().Err(err).Msg("unable to lift the write deadline of the export")
+	}
+
+	var writer rowWriter
+	contentType := csvContentType
+	if input.Format == exportFormatNDJSON {
+		writer = newNDJSONRowWriter(c.Writer)
+		contentType = ndjsonContentType
+	} else {
+		writer = newCSVRowWriter(c.Writer, input.Versions)
+	}
+
+	// Headers are only set once there is data to be written, so that errors which
+	// occur before that can still be reported as a regular JSON response.
+	var rows int
+	start := func() {
+		if rows == 0 {
+			c.Header("Content-Type", contentType)
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=services.%s", input.Format))
+			c.Status(http.StatusOK)
+		}
+	}
+	write := func(row interface{}) error {
+		start()
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushInterval == 0 {
+			writer.Flush()
+		}
+		return nil
+	}
+
+	var err error
+	if input.Versions {
+		err = models.StreamServiceVersions(c.Request.Context(), input.ListServicesInput, func(row models.ServiceVersionRow) error {
+			return write(row)
+		})
+	} else {
+		err = models.StreamServices(c.Request.Context(), input.ListServicesInput, func(svc models.Service) error {
+			return write(svc)
+		})
+	}
+	if err != nil {
+		if rows > 0 {
+			// The response has already started; all we can do is cut the stream short.
+			logging.FromContext(c.Request.Context()).Warn().Err(err).
+				Int("rows", rows).
+				Msg("export cut short")
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Error(err)
+		return
+	}
+
+	start()
+	if err := writer.Close(); err != nil {
+		c.Error(err)
+	}
+}
+
+// rowWriter writes exported rows in a specific format.
+type rowWriter interface {
+	Write(row interface{}) error
+	Flush()
+	Close() error
+}
+
+type ndjsonRowWriter struct {
+	w       gin.ResponseWriter
+	encoder *json.Encoder
+}
+
+func newNDJSONRowWriter(w gin.ResponseWriter) *ndjsonRowWriter {
+	return &ndjsonRowWriter{
+		w:       w,
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func (n *ndjsonRowWriter) Write(row interface{}) error {
+	return n.encoder.Encode(row)
+}
+
+func (n *ndjsonRowWriter) Flush() {
+	n.w.Flush()
+}
+
+func (n *ndjsonRowWriter) Close() error {
+	n.w.Flush()
+	return nil
+}
+
+var (
+	serviceCSVHeader = []string{
+		"id", "name", "description", "owner", "tags", "versions", "userID", "createdAt", "updatedAt",
+		"lifecycle", "tier", "repositoryURL",
+	}
+	serviceVersionCSVHeader = []string{
+		"serviceID", "name", "description", "owner", "tags", "userID", "serviceCreatedAt", "serviceUpdatedAt",
+		"version", "changelog", "versionCreatedAt",
+	}
+)
+
+// csvListSeparator separates the elements of list fields like tags in a single CSV cell.
+const csvListSeparator = ";"
+
+type csvRowWriter struct {
+	w             gin.ResponseWriter
+	csv           *csv.Writer
+	header        []string
+	headerWritten bool
+}
+
+func newCSVRowWriter(w gin.ResponseWriter, versions bool) *csvRowWriter {
+	header := serviceCSVHeader
+	if versions {
+		header = serviceVersionCSVHeader
+	}
+	return &csvRowWriter{
+		w:      w,
+		csv:    csv.NewWriter(w),
+		header: header,
+	}
+}
+
+func (c *csvRowWriter) Write(row interface{}) error {
+	if !c.headerWritten {
+		if err := c.csv.Write(c.header); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	var record []string
+	switch r := row.(type) {
+	case models.Service:
+		record = []string{
+			strconv.Itoa(int(r.ID)),
+			r.Name,
+			r.Description,
+			r.Owner,
+			strings.Join(r.Tags, csvListSeparator),
+			strings.Join(r.Versions, csvListSeparator),
+			strconv.Itoa(r.UserID),
+			r.CreatedAt.Format(time.RFC3339),
+			r.UpdatedAt.Format(time.RFC3339),
+			r.Lifecycle,
+			strconv.Itoa(r.Tier),
+			r.RepositoryURL,
+		}
+	case models.ServiceVersionRow:
+		var versionCreatedAt string
+		if r.VersionCreatedAt != nil {
+			versionCreatedAt = r.VersionCreatedAt.Format(time.RFC3339)
+		}
+		record = []string{
+			strconv.Itoa(int(r.ServiceID)),
+			r.Name,
+			r.Description,
+			r.Owner,
+			strings.Join(r.Tags, csvListSeparator),
+			strconv.Itoa(r.UserID),
+			r.ServiceCreatedAt.Format(time.RFC3339),
+			r.ServiceUpdatedAt.Format(time.RFC3339),
+			r.Version,
+			r.Changelog.String(),
+			versionCreatedAt,
+		}
+	default:
+		return fmt.Errorf("unsupported row type: %T", row)
+	}
+	return c.csv.Write(record)
+}
+
+func (c *csvRowWriter) Flush() {
+	c.csv.Flush()
+	c.w.Flush()
+}
+
+// Close writes the header if no rows were written and flushes any buffered data.
+func (c *csvRowWriter) Close() error {
+	if !c.headerWritten {
+		if err := c.csv.Write(c.header); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+	c.Flush()
+	return c.csv.Error()
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportServices(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		auth       bool
+		userID     uint
+		assertFunc func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "exporting services as csv",
+			path:   "/services/export?sortKey=name",
+			auth:   true,
+			userID: uint(1),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, w.Code)
+				assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+
+				records, err := csv.NewReader(w.Body).ReadAll()
+				assert.NoError(t, err)
+				assert.Len(t, records, 4)
+				assert.Equal(t, serviceCSVHeader, records[0])
+				assert.Equal(t, "auth", records[1][1])
+				assert.Equal(t, "1.0;1.1", records[1][5])
+			},
+		},
+		{
+			name:   "exporting services with versions as ndjson",
+			path:   "/services/export?format=ndjson&versions=true&name=storage",
+			auth:   true,
+			userID: uint(1),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, w.Code)
+				assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+				var rows []models.ServiceVersionRow
+				scanner := bufio.NewScanner(w.Body)
+				for scanner.Scan() {
+					var row models.ServiceVersionRow
+					if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+						t.Fatalf("Failed to unmarshal row: %v", err)
+					}
+					rows = append(rows, row)
+				}
+				assert.Len(t, rows, 2)
+				for _, row := range rows {
+					assert.Equal(t, "storage", row.Name)
+				}
+				assert.Equal(t, "0.1", rows[0].Version)
+				assert.Equal(t, "0.2", rows[1].Version)
+			},
+		},
+		{
+			name:   "exporting services with an invalid sort key returns a 400",
+			path:   "/services/export?sortKey=password",
+			auth:   true,
+			userID: uint(1),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 400, w.Code)
+				assert.Contains(t, w.Body.String(), models.ErrInvalidSortKey.Error())
+			},
+		},
+		{
+			name:   "exporting services in an unknown format returns a 400",
+			path:   "/services/export?format=xlsx",
+			auth:   true,
+			userID: uint(1),
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 400, w.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.path, nil)
+			assert.NoError(t, err)
+			if tt.auth {
+				err = addAuthorizationHeader(tt.userID, req)
+				assert.NoError(t, err)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			tt.assertFunc(t, w)
+		})
+	}
+}
+
+func TestExportWriteTimeout(t *testing.T) {
+	previous := serverConfig
+	t.Cleanup(func() {
+		serverConfig = previous
+	})
+
+	// The write deadline has passed before any handler runs.
+	cfg := config.Default().Server
+	cfg.WriteTimeout = time.Nanosecond
+	SetServerConfig(cfg)
+	server, err := NewServer(router)
+	assert.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go server.Serve(ln)
+	t.Cleanup(func() {
+		server.Close()
+	})
+	baseURL := "http://" + ln.Addr().String()
+
+	_, err = http.DefaultClient.Do(newRequest(t, "GET", baseURL+"/services", "", 1, nil))
+	assert.Error(t, err)
+
+	resp, err := http.DefaultClient.Do(newRequest(t, "GET", baseURL+"/services/export?format=ndjson", "", 1, nil))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"name":"auth"`)
+}
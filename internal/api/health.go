@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/auth"
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+)
+
+var healthCheckTimeout = config.Default().HealthCheck.Timeout
+
+// SetHealthCheckConfig sets the timeout of each readiness check.
+func SetHealthCheckConfig(cfg config.HealthCheck) {
+	healthCheckTimeout = cfg.Timeout
+}
+
+// HealthOutput represents the output returned by the health endpoints.
+type HealthOutput struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult represents the result of a single readiness check.
+type CheckResult struct {
+	Status   string      `json:"status"`
+	Duration string      `json:"duration"`
+	Error    string      `json:"error,omitempty"`
+	Details  interface{} `json:"details,omitempty"`
+}
+
+// healthCheck returns details about what it checked, or an error if the check failed.
+type healthCheck func(ctx context.Context) (interface{}, error)
+
+var readinessChecks = map[string]healthCheck{
+	"database": func(ctx context.Context) (interface{}, error) {
+		return nil, models.PingDB(ctx)
+	},
+	"migrations": func(ctx context.Context) (interface{}, error) {
+		status, err := models.GetMigrationStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if status.Pending() {
+			return status, fmt.Errorf("database schema is at version %d (dirty: %t), expected %d", status.Current, status.Dirty, status.Latest)
+		}
+		return status, nil
+	},
+	"signingKey": func(ctx context.Context) (interface{}, error) {
+		return nil, auth.CheckSigningKey()
+	},
+}
+
+// Healthz godoc
+// @Summary     Liveness probe
+// @Description Always succeeds while the process is able to serve requests.
+// @Produce     json
+// @Success     200  {object}  HealthOutput
+// @Router      /healthz [get]
+//
+// Healthz reports that the process is alive. It doesn't check any dependency, so
+// that an unavailable database doesn't get the process restarted.
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthOutput{
+		Status: healthStatusOK,
+	})
+}
+
+// Readyz godoc
+// @Summary     Readiness probe
+// @Description Checks the database connection, that all migrations were run and that the JWT signing key is configured.
+// @Produce     json
+// @Success     200  {object}  HealthOutput
+// @Failure     503  {object}  HealthOutput
+// @Router      /readyz [get]
+//
+// Readyz runs the readiness checks concurrently, each with its own timeout, and
+// reports whether the server can handle requests along with the result of each check.
+func Readyz(c *gin.Context) {
+	type result struct {
+		name string
+		CheckResult
+	}
+	results := make(chan result, len(readinessChecks))
+	for name, check := range readinessChecks {
+		go func(name string, check healthCheck) {
+			results <- result{name: name, CheckResult: runHealthCheck(c.Request.Context(), check)}
+		}(name, check)
+	}
+
+	output := HealthOutput{
+		Status: healthStatusOK,
+		Checks: make(map[string]CheckResult, len(readinessChecks)),
+	}
+	for range readinessChecks {
+		r := <-results
+		output.Checks[r.name] = r.CheckResult
+		if r.Status != healthStatusOK {
+			output.Status = healthStatusUnavailable
+		}
+	}
+
+	status := http.StatusOK
+	if output.Status != healthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, output)
+}
+
+// runHealthCheck runs the check, giving up once healthCheckTimeout elapsed even if
+// the check itself ignores the context.
+func runHealthCheck(ctx context.Context, check healthCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	type outcome struct {
+		details interface{}
+		err     error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		details, err := check(ctx)
+		done <- outcome{details: details, err: err}
+	}()
+
+	var o outcome
+	select {
+	case o = <-done:
+	case <-ctx.Done():
+		o.err = fmt.Errorf("timed out after %s", healthCheckTimeout)
+	}
+	result := CheckResult{
+		Status:   healthStatusOK,
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+		Details:  o.details,
+	}
+	if o.err != nil {
+		result.Status = healthStatusUnavailable
+		result.Error = o.err.Error()
+	}
+	return result
+}
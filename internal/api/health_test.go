@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		checks []string
+	}{
+		{name: "liveness", path: "/healthz"},
+		{name: "readiness", path: "/readyz", checks: []string{"database", "migrations", "signingKey"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.path, nil)
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, 200, w.Code)
+
+			var output HealthOutput
+			err = json.Unmarshal(w.Body.Bytes(), &output)
+			if err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			assert.Equal(t, "ok", output.Status)
+			assert.Len(t, output.Checks, len(tt.checks))
+			for _, check := range tt.checks {
+				assert.Equal(t, "ok", output.Checks[check].Status, output.Checks[check].Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuredLogger(t *testing.T) {
+	cfg := config.Default().Log
+	cfg.SampleSuccess = 3
+
+	tests := []struct {
+		name    string
+		level   zerolog.Level
+		headers bool
+	}{
+		{name: "info", level: zerolog.InfoLevel},
+		{name: "debug", level: zerolog.DebugLevel, headers: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := zerolog.New(&logs).Level(tt.level)
+			engine := gin.New()
+			engine.Use(middleware.StructuredLogger(&logger, cfg))
+			engine.GET("/ok", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			header := http.Header{
+				"Authorization": {"Bearer secret-token"},
+				"Accept":        {"application/json"},
+			}
+			for i := 0; i < 6; i++ {
+				serve(engine, newRequest(t, "GET", "/ok?Token=abc&q=x", "", 0, header))
+			}
+			serve(engine, newRequest(t, "GET", "/missing", "", 0, header))
+			serve(engine, newRequest(t, "GET", "/missing", "", 0, header))
+
+			lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+			// Successful requests are sampled, failed ones are always logged.
+			if !assert.Len(t, lines, 4) {
+				return
+			}
+			var paths []string
+			for _, line := range lines {
+				var entry map[string]interface{}
+				err := json.Unmarshal([]byte(line), &entry)
+				assert.NoError(t, err)
+				paths = append(paths, entry["path"].(string))
+				if tt.headers {
+					assert.Equal(t, map[string]interface{}{"Authorization": "REDACTED", "Accept": "application/json"}, entry["headers"])
+				} else {
+					assert.NotContains(t, entry, "headers")
+				}
+			}
+			assert.Equal(t, []string{"/ok?Token=REDACTED&q=x", "/ok?Token=REDACTED&q=x", "/missing", "/missing"}, paths)
+			assert.NotContains(t, logs.String(), "secret-token")
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	w := doRequest(t, "GET", "/services/1", "", 1, nil)
+	assert.Equal(t, 200, w.Code)
+
+	w = doRequest(t, "GET", "/metrics", "", 0, nil)
+	assert.Equal(t, 200, w.Code)
+
+	body := w.Body.String()
+	for _, metric := range []string{
+		`service_catalog_http_requests_total{method="GET",route="/services/:id",status="200"}`,
+		`service_catalog_http_request_duration_seconds_bucket{method="GET",route="/services/:id",status="200"`,
+		`service_catalog_db_query_duration_seconds_count{operation="query",table="services"}`,
+		`go_sql_open_connections{db_name="service_catalog"}`,
+		`service_catalog_services `,
+		`service_catalog_versions `,
+		`service_catalog_users `,
+	} {
+		assert.Contains(t, body, metric)
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aryan9600/service-catalog/internal/models"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+
+	acceptPatch = binding.MIMEJSON + ", " + mergePatchContentType + ", " + jsonPatchContentType
+)
+
+var (
+	// errInvalidPatch is returned if the patch document itself is malformed.
+	errInvalidPatch = &models.Error{Kind: models.ErrorKindValidation, Code: "invalid_patch", Message: "invalid patch"}
+	// errPatchConflict is returned if the patch can't be applied to the current
+	// state of the Service, e.g. because a 'test' operation failed.
+	errPatchConflict = &models.Error{Kind: models.ErrorKindConflict, Code: "patch_conflict", Message: "unable to apply patch"}
+	// errInvalidPatchResult is returned if the patched Service fails validation.
+	errInvalidPatchResult = &models.Error{Kind: models.ErrorKindUnprocessable, Code: "invalid_patch_result", Message: "invalid patched service"}
+)
+
+// servicePatchDocument represents the fields of a Service which can be modified
+// by a patch. Patches are applied to the JSON representation of this struct.
+type servicePatchDocument struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Owner       string   `json:"owner" binding:"max=255"`
+	Tags        []string `json:"tags" binding:"dive,max=50"`
+
+	Lifecycle     string              `json:"lifecycle" binding:"required,oneof=experimental production deprecated"`
+	Tier          int                 `json:"tier" binding:"min=0,max=4"`
+	RepositoryURL string              `json:"repositoryURL" binding:"omitempty,url,max=2048"`
+	Links         models.ServiceLinks `json:"links"`
+	Contacts      []models.Contact    `json:"contacts" binding:"max=20,dive"`
+	CustomFields  models.CustomFields `json:"customFields"`
+}
+
+// applyServicePatch applies the JSON Merge Patch or JSON Patch to the provided Service
+// and returns the input required to update the Service to the result.
+func applyServicePatch(svc models.Service, contentType string, patch []byte) (models.UpdateServiceInput, error) {
+	tags := []string(svc.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	contacts := []models.Contact(svc.Contacts)
+	if contacts == nil {
+		contacts = []models.Contact{}
+	}
+	customFields := svc.CustomFields
+	if customFields == nil {
+		customFields = models.CustomFields{}
+	}
+	original, err := json.Marshal(servicePatchDocument{
+		Name:          svc.Name,
+		Description:   svc.Description,
+		Owner:         svc.Owner,
+		Tags:          tags,
+		Lifecycle:     svc.Lifecycle,
+		Tier:          svc.Tier,
+		RepositoryURL: svc.RepositoryURL,
+		Links:         svc.Links,
+		Contacts:      contacts,
+		CustomFields:  customFields,
+	})
+	if err != nil {
+		return models.UpdateServiceInput{}, err
+	}
+
+	var patched []byte
+	if contentType == mergePatchContentType {
+		if !json.Valid(patch) {
+			return models.UpdateServiceInput{}, fmt.Errorf("%w: malformed JSON", errInvalidPatch)
+		}
+		patched, err = jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return models.UpdateServiceInput{}, fmt.Errorf("%w: %s", errInvalidPatch, err.Error())
+		}
+	} else {
+		ops, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return models.UpdateServiceInput{}, fmt.Errorf("%w: %s", errInvalidPatch, err.Error())
+		}
+		patched, err = ops.Apply(original)
+		if err != nil {
+			return models.UpdateServiceInput{}, fmt.Errorf("%w: %s", errPatchConflict, err.Error())
+		}
+	}
+
+	var doc servicePatchDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return models.UpdateServiceInput{}, fmt.Errorf("%w: %s", errInvalidPatchResult, err.Error())
+	}
+	if err := binding.Validator.ValidateStruct(&doc); err != nil {
+		invalid := *errInvalidPatchResult
+		invalid.Message = fmt.Sprintf("%s: %s", errInvalidPatchResult.Message, err.Error())
+		invalid.Fields = fieldErrors(err)
+		return models.UpdateServiceInput{}, &invalid
+	}
+
+	if doc.Tags == nil {
+		doc.Tags = []string{}
+	}
+	if doc.Contacts == nil {
+		doc.Contacts = []models.Contact{}
+	}
+	if doc.CustomFields == nil {
+		doc.CustomFields = models.CustomFields{}
+	}
+	return models.UpdateServiceInput{
+		Name:          &doc.Name,
+		Description:   &doc.Description,
+		Owner:         &doc.Owner,
+		Tags:          doc.Tags,
+		Lifecycle:     &doc.Lifecycle,
+		Tier:          &doc.Tier,
+		RepositoryURL: &doc.RepositoryURL,
+		Links:         &doc.Links,
+		Contacts:      doc.Contacts,
+		CustomFields:  doc.CustomFields,
+	}, nil
+}
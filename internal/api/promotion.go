@@ -0,0 +1,244 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionStateOutput represents the output returned while fetching or changing
+// the PromotionState of a Version.
+type PromotionStateOutput struct {
+	Data models.PromotionState `json:"data"`
+}
+
+// ListMaintainersOutput represents the output returned while listing the maintainers of a Service.
+type ListMaintainersOutput struct {
+	Data []models.Maintainer `json:"data"`
+}
+
+// GetPromotionState godoc
+// @Summary     Get the promotion state of a version
+// @Description Returns the stages the version was promoted to and what blocks its promotion to the next stage.
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Success     200  {object}  PromotionStateOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /services/{id}/versions/{version}/promotion [get]
+//
+// GetPromotionState returns the PromotionState of the provided Version.
+func GetPromotionState(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	state, err := models.GetPromotionState(c.Request.Context(), uint(svcId), userID, c.Param("version"))
+	if err != nil {
+		c.Error(fmt.Errorf("unable to fetch promotion state: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, PromotionStateOutput{
+		Data: *state,
+	})
+}
+
+// PromoteVersion godoc
+// @Summary     Promote a version to the next stage
+// @Description Promotions are rejected with a 409 listing the reasons unless the version soaked long enough in its current stage and enough maintainers approved the promotion.
+// @Accept      json
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Param       promotion body   models.PromoteVersionInput false  "Promotion JSON"
+// @Success     200  {object}  PromotionStateOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /services/{id}/versions/{version}/promote [post]
+//
+// PromoteVersion promotes the provided Version to its next stage.
+func PromoteVersion(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	// The body is optional since the stage defaults to the next one.
+	var input models.PromoteVersionInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(invalidInput("invalid promotion input", err))
+			return
+		}
+	}
+
+	state, err := models.PromoteVersion(c.Request.Context(), uint(svcId), userID, c.Param("version"), input)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to promote version: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, PromotionStateOutput{
+		Data: *state,
+	})
+}
+
+// ApprovePromotion godoc
+// @Summary     Approve the promotion of a version to the next stage
+// @Accept      json
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Param       approval body   models.ApprovePromotionInput false  "Approval JSON"
+// @Success     200  {object}  PromotionStateOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /services/{id}/versions/{version}/approvals [post]
+//
+// ApprovePromotion records that the user, who must own or maintain the Service,
+// approves the promotion of the provided Version.
+func ApprovePromotion(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	var input models.ApprovePromotionInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(invalidInput("invalid approval input", err))
+			return
+		}
+	}
+
+	state, err := models.ApprovePromotion(c.Request.Context(), uint(svcId), userID, c.Param("version"), input)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to approve promotion: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, PromotionStateOutput{
+		Data: *state,
+	})
+}
+
+// ListMaintainers godoc
+// @Summary List the maintainers of a service
+// @Produce json
+// @Param   Authorization header string true "Bearer token"
+// @Success 200  {object}  ListMaintainersOutput
+// @Failure default  {object}  middleware.Problem
+// @Router  /services/{id}/maintainers [get]
+//
+// ListMaintainers returns the users who, besides its owner, maintain the provided Service.
+func ListMaintainers(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	maintainers, err := models.ListMaintainers(c.Request.Context(), uint(svcId), userID)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to list maintainers: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, ListMaintainersOutput{
+		Data: maintainers,
+	})
+}
+
+// SetMaintainers godoc
+// @Summary Replace the maintainers of a service
+// @Accept  json
+// @Produce json
+// @Param   Authorization header string true "Bearer token"
+// @Param   maintainers body   models.SetMaintainersInput true  "Maintainers JSON"
+// @Success 200  {object}  ListMaintainersOutput
+// @Failure default  {object}  middleware.Problem
+// @Router  /services/{id}/maintainers [put]
+//
+// SetMaintainers replaces the maintainers of the provided Service. Only its
+// owner can change them.
+func SetMaintainers(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	var input models.SetMaintainersInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput("invalid maintainers input", err))
+		return
+	}
+
+	maintainers, err := models.SetMaintainers(c.Request.Context(), uint(svcId), userID, input)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to set maintainers: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, ListMaintainersOutput{
+		Data: maintainers,
+	})
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotions(t *testing.T) {
+	models.SetPromotionConfig(config.Promotion{
+		MinSoakTime:       map[string]time.Duration{"prod": time.Hour},
+		RequiredApprovals: map[string]int{"staging": 1},
+	})
+	t.Cleanup(func() {
+		models.SetPromotionConfig(config.Promotion{})
+	})
+
+	decodeState := func(w *httptest.ResponseRecorder) models.PromotionState {
+		var output PromotionStateOutput
+		err := json.Unmarshal(w.Body.Bytes(), &output)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return output.Data
+	}
+
+	w := doRequest(t, "POST", "/services", `{"name": "checkout"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var created ServiceOutput
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	svcPath := fmt.Sprintf("/services/%d", created.Data.ID)
+	versionPath := svcPath + "/versions/1.0"
+	w = doRequest(t, "POST", svcPath+"/version", `{"version": "1.0"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+
+	t.Run("maintainers", func(t *testing.T) {
+		w := doRequest(t, "PUT", svcPath+"/maintainers", `{"usernames": ["nobody"]}`, 2, nil)
+		assert.Equal(t, 400, w.Code)
+
+		w = doRequest(t, "PUT", svcPath+"/maintainers", `{"usernames": ["user1"]}`, 1, nil)
+		assert.Equal(t, 404, w.Code)
+
+		w = doRequest(t, "GET", versionPath+"/promotion", "", 1, nil)
+		assert.Equal(t, 404, w.Code)
+
+		w = doRequest(t, "PUT", svcPath+"/maintainers", `{"usernames": ["user1"]}`, 2, nil)
+		assert.Equal(t, 200, w.Code)
+		var output ListMaintainersOutput
+		err := json.Unmarshal(w.Body.Bytes(), &output)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if assert.Len(t, output.Data, 1) {
+			assert.Equal(t, "user1", output.Data[0].Username)
+		}
+
+		w = doRequest(t, "GET", svcPath+"/maintainers", "", 1, nil)
+		assert.Equal(t, 200, w.Code)
+	})
+
+	w = doRequest(t, "GET", versionPath+"/promotion", "", 1, nil)
+	assert.Equal(t, 200, w.Code)
+	state := decodeState(w)
+	assert.Equal(t, "", state.Stage)
+	assert.Equal(t, "dev", state.NextStage)
+	assert.Empty(t, state.Blockers)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		userID uint
+		code   int
+		stage  string
+		// problemCode is checked for rejected promotions.
+		problemCode string
+	}{
+		{name: "unknown stage", method: "POST", path: "/promote", body: `{"stage": "qa"}`, userID: 2, code: 400},
+		{name: "skip a stage", method: "POST", path: "/promote", body: `{"stage": "staging"}`, userID: 2, code: 409, problemCode: "promotion_rejected"},
+		{name: "promote to dev", method: "POST", path: "/promote", userID: 2, code: 200, stage: "dev"},
+		{name: "promote to staging without approvals", method: "POST", path: "/promote", userID: 1, code: 409, problemCode: "promotion_rejected"},
+		{name: "approve the promotion to staging", method: "POST", path: "/approvals", userID: 1, code: 200, stage: "dev"},
+		{name: "approve twice", method: "POST", path: "/approvals", body: `{"stage": "staging"}`, userID: 1, code: 200, stage: "dev"},
+		{name: "promote to staging with an own approval", method: "POST", path: "/promote", userID: 1, code: 409, problemCode: "promotion_rejected"},
+		{name: "promote to staging", method: "POST", path: "/promote", userID: 2, code: 200, stage: "staging"},
+		{name: "approve a past stage", method: "POST", path: "/approvals", body: `{"stage": "dev"}`, userID: 2, code: 400},
+		{name: "promote to prod before the soak time", method: "POST", path: "/promote", userID: 2, code: 409, problemCode: "promotion_rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(t, tt.method, versionPath+tt.path, tt.body, tt.userID, nil)
+			assert.Equal(t, tt.code, w.Code)
+			if tt.stage != "" {
+				assert.Equal(t, tt.stage, decodeState(w).Stage)
+			}
+			if tt.problemCode != "" {
+				var problem middleware.Problem
+				err := json.Unmarshal(w.Body.Bytes(), &problem)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Equal(t, tt.problemCode, problem.Code)
+				assert.NotEmpty(t, problem.Errors)
+			}
+		})
+	}
+
+	w = doRequest(t, "GET", versionPath+"/promotion", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	state = decodeState(w)
+	assert.Equal(t, "staging", state.Stage)
+	assert.Equal(t, "prod", state.NextStage)
+	assert.Len(t, state.Promotions, 2)
+	if assert.Len(t, state.Blockers, 1) {
+		assert.Contains(t, state.Blockers[0], "must soak in staging")
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/aryan9600/service-catalog/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+var rateLimitConfig = config.Default().RateLimit
+
+// SetRateLimitConfig sets the rate limits of the router. It must be called before NewRouter.
+func SetRateLimitConfig(cfg config.RateLimit) {
+	rateLimitConfig = cfg
+}
+
+// rateLimits are the middlewares limiting the rate of requests of each route group.
+type rateLimits struct {
+	auth     gin.HandlerFunc
+	services gin.HandlerFunc
+	catalog  gin.HandlerFunc
+	other    gin.HandlerFunc
+	// clientIP limits requests to authenticated endpoints per client IP. It's used
+	// before JwtAuthMiddleware, so that requests which fail to authenticate count.
+	clientIP gin.HandlerFunc
+}
+
+// newRateLimits returns the middlewares enforcing the configured rate limits, which
+// let all requests through if rate limiting is disabled.
+func newRateLimits() rateLimits {
+	if !rateLimitConfig.Enabled {
+		next := func(c *gin.Context) {
+			c.Next()
+		}
+		return rateLimits{auth: next, services: next, catalog: next, other: next, clientIP: next}
+	}
+
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+	if rateLimitConfig.Store == "postgres" {
+		limiter = models.NewDatabaseRateLimiter()
+	}
+	limit := func(name string, cfg config.RateLimitPolicy) gin.HandlerFunc {
+		return middleware.RateLimit(limiter, ratelimit.Policy{
+			Name:     name,
+			Requests: cfg.Requests,
+			Period:   cfg.Period,
+			Burst:    cfg.Burst,
+		})
+	}
+	return rateLimits{
+		auth:     limit("auth", rateLimitConfig.Auth),
+		services: limit("services", rateLimitConfig.Services),
+		catalog:  limit("catalog", rateLimitConfig.Catalog),
+		other:    limit("default", rateLimitConfig.Default),
+		clientIP: limit("client_ip", rateLimitConfig.ClientIP),
+	}
+}
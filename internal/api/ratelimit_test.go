@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/aryan9600/service-catalog/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiters := []struct {
+		name    string
+		limiter ratelimit.Limiter
+	}{
+		{name: "memory", limiter: ratelimit.NewMemoryLimiter()},
+		{name: "database", limiter: models.NewDatabaseRateLimiter()},
+	}
+	for _, l := range limiters {
+		t.Run(l.name, func(t *testing.T) {
+			policy := ratelimit.Policy{Name: "test", Requests: 1, Period: time.Hour, Burst: 2}
+			engine := gin.New()
+			engine.Use(middleware.ErrorHandler())
+			ok := func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			}
+			engine.GET("/anonymous", middleware.RateLimit(l.limiter, policy), ok)
+			engine.GET("/user", func(c *gin.Context) {
+				c.Set("userID", uint(7))
+			}, middleware.RateLimit(l.limiter, policy), ok)
+
+			doRequest := func(path, remoteAddr string) *httptest.ResponseRecorder {
+				req := newRequest(t, "GET", path, "", 0, nil)
+				req.RemoteAddr = remoteAddr
+				return serve(engine, req)
+			}
+
+			for _, remaining := range []string{"1", "0"} {
+				w := doRequest("/anonymous", "192.0.2.1:1234")
+				assert.Equal(t, 200, w.Code)
+				assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+				assert.Equal(t, remaining, w.Header().Get("RateLimit-Remaining"))
+				assert.Equal(t, "1;w=3600;burst=2", w.Header().Get("RateLimit-Policy"))
+			}
+
+			w := doRequest("/anonymous", "192.0.2.1:1234")
+			assert.Equal(t, 429, w.Code)
+			assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+			assert.Equal(t, "7200", w.Header().Get("RateLimit-Reset"))
+			var problem middleware.Problem
+			err := json.Unmarshal(w.Body.Bytes(), &problem)
+			if err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			assert.Equal(t, "rate_limited", problem.Code)
+
+			// Other clients and users have their own buckets.
+			w = doRequest("/anonymous", "198.51.100.1:1234")
+			assert.Equal(t, 200, w.Code)
+			w = doRequest("/user", "192.0.2.1:1234")
+			assert.Equal(t, 200, w.Code)
+		})
+	}
+}
+
+func TestRateLimitTrustedProxies(t *testing.T) {
+	previousRateLimit, previousServer := rateLimitConfig, serverConfig
+	t.Cleanup(func() {
+		SetRateLimitConfig(previousRateLimit)
+		SetServerConfig(previousServer)
+	})
+	cfg := config.Default().RateLimit
+	cfg.Auth = config.RateLimitPolicy{Requests: 1, Period: time.Hour, Burst: 1}
+	SetRateLimitConfig(cfg)
+
+	login := func(engine *gin.Engine, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		req := newRequest(t, "POST", "/auth/login", `{}`, 0, http.Header{"X-Forwarded-For": {forwardedFor}})
+		req.RemoteAddr = remoteAddr
+		return serve(engine, req)
+	}
+
+	// Without trusted proxies, a spoofed X-Forwarded-For header doesn't get a client
+	// a fresh bucket.
+	SetServerConfig(config.Default().Server)
+	engine := NewRouter()
+	w := login(engine, "192.0.2.1:1234", "203.0.113.1")
+	assert.NotEqual(t, 429, w.Code)
+	w = login(engine, "192.0.2.1:1234", "203.0.113.2")
+	assert.Equal(t, 429, w.Code)
+
+	// Behind a trusted proxy, clients are told apart by the header it sets.
+	server := config.Default().Server
+	server.TrustedProxies = []string{"192.0.2.0/24"}
+	SetServerConfig(server)
+	engine = NewRouter()
+	w = login(engine, "192.0.2.1:1234", "203.0.113.1")
+	assert.NotEqual(t, 429, w.Code)
+	w = login(engine, "192.0.2.1:1234", "203.0.113.2")
+	assert.NotEqual(t, 429, w.Code)
+	w = login(engine, "192.0.2.2:1234", "203.0.113.2")
+	assert.Equal(t, 429, w.Code)
+}
+
+func TestRateLimitBeforeAuthentication(t *testing.T) {
+	previous := rateLimitConfig
+	t.Cleanup(func() {
+		SetRateLimitConfig(previous)
+	})
+	cfg := config.Default().RateLimit
+	cfg.ClientIP = config.RateLimitPolicy{Requests: 1, Period: time.Hour, Burst: 1}
+	SetRateLimitConfig(cfg)
+	engine := NewRouter()
+
+	get := func(remoteAddr string, userID uint, header http.Header) *httptest.ResponseRecorder {
+		req := newRequest(t, "GET", "/services", "", userID, header)
+		req.RemoteAddr = remoteAddr
+		return serve(engine, req)
+	}
+
+	// Requests with invalid tokens are limited, although they never get to a user's bucket.
+	w := get("192.0.2.1:1234", 0, http.Header{"Authorization": {"Bearer invalid"}})
+	assert.Equal(t, 401, w.Code)
+	w = get("192.0.2.1:1234", 0, http.Header{"Authorization": {"Bearer invalid"}})
+	assert.Equal(t, 429, w.Code)
+	w = get("192.0.2.1:1234", 1, nil)
+	assert.Equal(t, 429, w.Code)
+
+	w = get("198.51.100.1:1234", 1, nil)
+	assert.Equal(t, 200, w.Code)
+}
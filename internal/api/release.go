@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// FindVersionsOutput represents the output returned while looking up Versions by their release metadata.
+type FindVersionsOutput struct {
+	Data []models.ServiceVersion `json:"data"`
+}
+
+// FindVersions godoc
+// @Summary     Look up versions by commit or container image digest.
+// @Description Searches the versions of all services in the catalog, not only the user's own. Exactly one of 'commit' and 'imageDigest' is required.
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Param       commit query string false "Git commit SHA, or a prefix of at least 7 characters"
+// @Param       imageDigest query string false "Container image digest, e.g. sha256:<hex>"
+// @Success     200  {object}  FindVersionsOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /versions [get]
+//
+// FindVersions returns the Versions which were built from the provided commit or
+// published as the provided container image, along with their Services.
+func FindVersions(c *gin.Context) {
+	var input models.FindVersionsInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.Error(invalidInput("invalid query parameters", err))
+		return
+	}
+	versions, err := models.FindVersions(c.Request.Context(), input)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to find versions: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, FindVersionsOutput{
+		Data: versions,
+	})
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseMetadata(t *testing.T) {
+	commit := "3f786850e387550fdab836ed7e6dc881de23001b"
+	digest := "sha256:" + strings.Repeat("a1", 32)
+
+	w := doRequest(t, "POST", "/services", `{"name": "mailer"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var created ServiceOutput
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	svcPath := fmt.Sprintf("/services/%d", created.Data.ID)
+
+	w = doRequest(t, "POST", svcPath+"/version", `{"version": "1.0.0", "release": {
+		"commit": "3f78685",
+		"imageDigest": "sha256:abc",
+		"artifacts": [{"name": "mailer-linux-amd64", "url": "not a url"}]
+	}}`, 2, nil)
+	assert.Equal(t, 400, w.Code)
+	var problem middleware.Problem
+	err = json.Unmarshal(w.Body.Bytes(), &problem)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.Len(t, problem.Errors, 1) {
+		assert.Equal(t, "release.artifacts[0].url", problem.Errors[0].Field)
+	}
+
+	w = doRequest(t, "POST", svcPath+"/version", `{"version": "1.0.0", "release": {"commit": "3f78685", "imageDigest": "sha256:abc"}}`, 2, nil)
+	assert.Equal(t, 400, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &problem)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Len(t, problem.Errors, 2)
+
+	w = doRequest(t, "POST", svcPath+"/version", fmt.Sprintf(`{"version": "1.0.0", "release": {
+		"commit": "%s",
+		"buildTime": "2024-01-02T15:04:05Z",
+		"imageDigest": "%s",
+		"artifacts": [{"name": "mailer-linux-amd64", "url": "https://example.com/mailer-linux-amd64"}]
+	}}`, strings.ToUpper(commit), digest), 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var version CreateVersionOutput
+	err = json.Unmarshal(w.Body.Bytes(), &version)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Equal(t, commit, version.Data.Release.Commit)
+	assert.Len(t, version.Data.Release.Artifacts, 1)
+
+	w = doRequest(t, "GET", svcPath, "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	var svc GetServiceWithVersionsOutput
+	err = json.Unmarshal(w.Body.Bytes(), &svc)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.Len(t, svc.Data.Versions, 1) {
+		assert.Equal(t, digest, svc.Data.Versions[0].Release.ImageDigest)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		userID   uint
+		code     int
+		versions int
+	}{
+		{name: "commit", query: "commit=" + commit, userID: 2, code: 200, versions: 1},
+		{name: "commit prefix", query: "commit=" + commit[:7], userID: 2, code: 200, versions: 1},
+		{name: "image digest", query: "imageDigest=" + digest, userID: 2, code: 200, versions: 1},
+		{name: "other user's service", query: "imageDigest=" + digest, userID: 1, code: 200, versions: 1},
+		{name: "short prefix", query: "commit=3f78", userID: 2, code: 400},
+		{name: "invalid digest", query: "imageDigest=md5:abc", userID: 2, code: 400},
+		{name: "no filter", query: "", userID: 2, code: 400},
+		{name: "both filters", query: "commit=" + commit + "&imageDigest=" + digest, userID: 2, code: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(t, "GET", "/versions?"+tt.query, "", tt.userID, nil)
+			assert.Equal(t, tt.code, w.Code)
+			if tt.code != 200 {
+				return
+			}
+			var output FindVersionsOutput
+			err := json.Unmarshal(w.Body.Bytes(), &output)
+			if err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if assert.Len(t, output.Data, tt.versions) && tt.versions > 0 {
+				assert.Equal(t, "mailer", output.Data[0].ServiceName)
+				assert.Equal(t, "1.0.0", output.Data[0].Version.Version)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aryan9600/service-catalog/internal/logging"
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	previous := *logging.FromContext(context.Background())
+	logging.SetLogger(zerolog.New(&logs))
+	t.Cleanup(func() {
+		logging.SetLogger(previous)
+	})
+
+	tests := []struct {
+		name      string
+		requestID string
+		generated bool
+	}{
+		{name: "generated", generated: true},
+		{name: "accepted", requestID: "client-id:42"},
+		{name: "too long", requestID: strings.Repeat("a", 129), generated: true},
+		{name: "not printable", requestID: "id\tinjected", generated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(t, "GET", "/services", "", 2, http.Header{middleware.RequestIDHeader: {tt.requestID}})
+			assert.Equal(t, 200, w.Code)
+			id := w.Header().Get(middleware.RequestIDHeader)
+			if tt.generated {
+				assert.Regexp(t, `^[0-9a-f]{32}$`, id)
+			} else {
+				assert.Equal(t, tt.requestID, id)
+			}
+		})
+	}
+
+	w := doRequest(t, "GET", "/services/999999", "", 2, http.Header{middleware.RequestIDHeader: {"not-found-id"}})
+	assert.Equal(t, 404, w.Code)
+	var problem middleware.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &problem)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Equal(t, "not-found-id", problem.RequestID)
+
+	// The request ID is recorded with audit records and included in log lines of the models layer.
+	w = doRequest(t, "POST", "/services/5/deployments", `{"version": "beta", "environment": "dev"}`, 2, http.Header{middleware.RequestIDHeader: {"deploy-id"}})
+	assert.Equal(t, 201, w.Code)
+	var deployment DeploymentOutput
+	err = json.Unmarshal(w.Body.Bytes(), &deployment)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.NotNil(t, deployment.Data.RequestID) {
+		assert.Equal(t, "deploy-id", *deployment.Data.RequestID)
+	}
+	assert.Contains(t, logs.String(), `"request_id":"deploy-id"`)
+	assert.Contains(t, logs.String(), `"message":"deployment recorded"`)
+}
@@ -1,52 +1,143 @@
 package api
 
 import (
-	"os"
+	"io"
 
 	"github.com/aryan9600/service-catalog/docs"
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/logging"
+	"github.com/aryan9600/service-catalog/internal/metrics"
 	"github.com/aryan9600/service-catalog/internal/middleware"
 	"github.com/gin-gonic/gin"
 
-	"github.com/natefinch/lumberjack"
-	"github.com/rs/zerolog"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+var (
+	logConfig      = config.Default().Log
+	corsConfig     = config.Default().CORS
+	securityConfig = config.Default().Security
+	// logSink is where the logger of the router writes to.
+	logSink io.Closer
+)
+
+// SetLogConfig sets where and what the router logs. It must be called before NewRouter.
+func SetLogConfig(cfg config.Log) {
+	logConfig = cfg
+}
+
+// SetCORSConfig sets the CORS policy of the router. It must be called before NewRouter.
+func SetCORSConfig(cfg config.CORS) {
+	corsConfig = cfg
+}
+
+// SetSecurityHeadersConfig sets the security headers of the responses of the router.
+// It must be called before NewRouter.
+func SetSecurityHeadersConfig(cfg config.Security) {
+	securityConfig = cfg
+}
+
 // NewRouter returns a Gin router configured with all endpoints and middleware.
 func NewRouter() *gin.Engine {
-	fileName := os.Getenv("LOG_FILE")
-	if fileName == "" {
-		fileName = "file.log"
-	}
-	z := zerolog.New(&lumberjack.Logger{
-		Filename:   fileName,
-		MaxSize:    100,
-		MaxBackups: 5,
-		MaxAge:     30,
-		Compress:   true,
-	})
+	z, sink := logging.New(logConfig)
+	logSink = sink
+	logging.SetLogger(z)
 
 	docs.SwaggerInfo.Title = "Service Catalog"
+	useJSONFieldNames()
 
-	router := gin.Default()
-	router.Use(middleware.StructuredLogger(&z))
+	router := gin.New()
+	// Rate limits and logs rely on the client IP, so forwarded headers are only used
+	// if they were set by a trusted proxy. If the proxies are invalid, none is trusted.
+	if err := router.SetTrustedProxies(serverConfig.TrustedProxies); err != nil {
+		z.Error().Err(err).Msg("unable to set the trusted proxies")
+	}
+	router.Use(gin.Recovery())
+	router.Use(middleware.Tracing())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Metrics())
+	router.Use(middleware.StructuredLogger(&z, logConfig))
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.SecurityHeaders(securityConfig))
+	router.Use(middleware.CORS(corsConfig))
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(errNotFound)
+	})
 
+	router.GET("/healthz", Healthz)
+	router.GET("/readyz", Readyz)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	limits := newRateLimits()
+
 	auth := router.Group("auth")
+	auth.Use(limits.auth)
 	auth.POST("/register", Register)
 	auth.POST("/login", Login)
 
 	services := router.Group("services")
-	services.Use(middleware.JwtAuthMiddleware())
+	services.Use(limits.clientIP, middleware.JwtAuthMiddleware(), limits.services)
 
 	services.GET("", ListServices)
 	services.POST("", CreateService)
+	services.GET("export", ExportServices)
+	services.GET("by-slug/:slug", GetServiceBySlug)
 	services.GET(":id", GetService)
+	services.GET(":id/renames", ListServiceRenames)
+	services.GET(":id/changelog", GetChangelog)
 	services.PATCH(":id", UpdateService)
 
 	services.POST(":id/version", CreateVersion)
+	services.PUT(":id/versions/:version/spec", PutVersionSpec)
+	services.GET(":id/versions/:version/spec", GetVersionSpec)
+	services.DELETE(":id/versions/:version/spec", DeleteVersionSpec)
+	services.GET(":id/versions/:version/compatibility", GetCompatibilityReport)
+	services.GET(":id/versions/:version/promotion", GetPromotionState)
+	services.POST(":id/versions/:version/promote", PromoteVersion)
+	services.POST(":id/versions/:version/approvals", ApprovePromotion)
+	services.POST(":id/deployments", CreateDeployment)
+	services.GET(":id/deployments", ListDeployments)
+	services.GET(":id/maintainers", ListMaintainers)
+	services.PUT(":id/maintainers", SetMaintainers)
+
+	versions := router.Group("versions")
+	versions.Use(limits.clientIP, middleware.JwtAuthMiddleware(), limits.other)
+
+	versions.GET("", FindVersions)
+
+	deployments := router.Group("deployments")
+	deployments.Use(limits.clientIP, middleware.JwtAuthMiddleware(), limits.other)
+
+	deployments.GET("current", ListCurrentDeployments)
+
+	router.GET("environments", limits.clientIP, middleware.JwtAuthMiddleware(), limits.other, ListEnvironments)
+
+	router.POST("services:method", limits.clientIP, middleware.JwtAuthMiddleware(), limits.services, serviceMethods(map[string]gin.HandlerFunc{
+		"batch": BatchServices,
+	}))
+
+	schemas := router.Group("schemas")
+	schemas.Use(limits.clientIP, middleware.JwtAuthMiddleware(), limits.other)
+
+	schemas.GET("custom-fields", GetCustomFieldSchema)
+	schemas.PUT("custom-fields", middleware.AdminOnly(), RegisterCustomFieldSchema)
+
+	catalog := router.Group("")
+	catalog.Use(limits.clientIP, middleware.JwtAuthMiddleware(), limits.catalog)
+
+	catalog.POST("import", ImportCatalog)
+	catalog.GET("export", ExportCatalog)
 
 	return router
 }
+
+// CloseLog closes the sink the router logs to. It must only be called once the
+// router stopped serving requests.
+func CloseLog() error {
+	if logSink == nil {
+		return nil
+	}
+	return logSink.Close()
+}
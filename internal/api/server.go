@@ -0,0 +1,71 @@
+package api
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/certs"
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+var serverConfig = config.Default().Server
+
+var (
+	tlsVersions = map[string]uint16{
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+	clientAuthTypes = map[string]tls.ClientAuthType{
+		"none":     tls.NoClientCert,
+		"optional": tls.VerifyClientCertIfGiven,
+		"require":  tls.RequireAndVerifyClientCert,
+	}
+)
+
+// SetServerConfig sets the port, timeouts, trusted proxies and TLS settings of the
+// HTTP server. It must be called before NewRouter.
+func SetServerConfig(cfg config.Server) {
+	serverConfig = cfg
+}
+
+// ShutdownTimeout returns how long in-flight requests are given to complete on shutdown.
+func ShutdownTimeout() time.Duration {
+	return serverConfig.ShutdownTimeout
+}
+
+// NewServer returns an HTTP server serving the router with the configured port and
+// timeouts. If a certificate is configured, the server's TLSConfig is set and it
+// must be started with ListenAndServeTLS("", "").
+func NewServer(router *gin.Engine) (*http.Server, error) {
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%s", serverConfig.Port),
+		Handler:           router,
+		ReadTimeout:       serverConfig.ReadTimeout,
+		ReadHeaderTimeout: serverConfig.ReadHeaderTimeout,
+		WriteTimeout:      serverConfig.WriteTimeout,
+		IdleTimeout:       serverConfig.IdleTimeout,
+	}
+	cfg := serverConfig.TLS
+	if cfg.CertFile == "" {
+		return server, nil
+	}
+
+	clientAuth := clientAuthTypes[cfg.ClientAuth]
+	clientCAFile := ""
+	if clientAuth != tls.NoClientCert {
+		clientCAFile = cfg.ClientCAFile
+	}
+	reloader, err := certs.NewReloader(cfg.CertFile, cfg.KeyFile, clientCAFile, cfg.ReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+	server.TLSConfig = reloader.TLSConfig(&tls.Config{
+		MinVersion: tlsVersions[cfg.MinVersion],
+		ClientAuth: clientAuth,
+		NextProtos: []string{"h2", "http/1.1"},
+	})
+	return server, nil
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerConfig(t *testing.T) {
+	previous := serverConfig
+	t.Cleanup(func() {
+		serverConfig = previous
+	})
+
+	cfg := config.Default().Server
+	cfg.Port = "9090"
+	cfg.WriteTimeout = 2 * time.Minute
+	cfg.IdleTimeout = 0
+	SetServerConfig(cfg)
+
+	server, err := NewServer(router)
+	assert.NoError(t, err)
+	assert.Equal(t, ":9090", server.Addr)
+	assert.Equal(t, 15*time.Second, server.ReadTimeout)
+	assert.Equal(t, 2*time.Minute, server.WriteTimeout)
+	assert.Equal(t, time.Duration(0), server.IdleTimeout)
+}
@@ -1,13 +1,15 @@
 package api
 
 import (
-	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
+	"github.com/aryan9600/service-catalog/internal/middleware"
 	"github.com/aryan9600/service-catalog/internal/models"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // ListServicesOutput represents the output returned when fetching a list of Services.
@@ -33,6 +35,12 @@ type ServiceOutput struct {
 	Data models.Service `json:"data"`
 }
 
+// ListServiceRenamesOutput represents the output returned when fetching the rename
+// history of a Service.
+type ListServiceRenamesOutput struct {
+	Data []models.ServiceRename `json:"data"`
+}
+
 // CreateVersionOutput represnets the output returned after creating a Version object.
 type CreateVersionOutput struct {
 	Data models.Version `json:"data"`
@@ -47,19 +55,27 @@ type CreateVersionOutput struct {
 // @Param       sortKey query string false "Key to sort records by"
 // @Param       descending query bool false "Sort records in descending order"
 // @Param       name query string false "Search records by name"
+// @Param       lifecycle query string false "Filter by lifecycle" Enums(experimental, production, deprecated)
+// @Param       tier query int false "Filter by criticality tier, 0 for unclassified" minimum(0) maximum(4)
+// @Param       repositoryURL query string false "Filter by repository URL"
+// @Param       hasLink query string false "Only return services with this link" Enums(runbook, dashboard, docs)
+// @Param       contactType query string false "Only return services with a contact of this type"
+// @Param       contact query string false "Only return services with a contact with this value"
+// @Param       customField query []string false "Only return services whose custom fields match, e.g. 'team:payments'" collectionFormat(multi)
 // @Success     200  {object}  ListServicesOutput
+// @Failure     default  {object}  middleware.Problem
 // @Router      /services [get]
 //
 // ListServices returns a list of services for the authenticated user based on the following query parameters:
 func ListServices(c *gin.Context) {
 	uID, ok := c.Get("userID")
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "unable to fetch user details"})
+		c.Error(errUserDetails)
 		return
 	}
 	userID, ok := uID.(uint)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "unable to fetch user details"})
+		c.Error(errUserDetails)
 		return
 	}
 
@@ -67,13 +83,13 @@ func ListServices(c *gin.Context) {
 	input.UserID = userID
 
 	if err := c.ShouldBindQuery(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("invalid query parameters: %s", err.Error())})
+		c.Error(invalidInput("invalid query parameters", err))
 		return
 	}
 
-	services, err := models.ListServices(input)
+	services, err := models.ListServices(c.Request.Context(), input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": fmt.Sprintf("unable to list services: %s", err.Error())})
+		c.Error(fmt.Errorf("unable to list services: %w", err))
 		return
 	}
 	c.JSON(http.StatusOK, ListServicesOutput{
@@ -87,29 +103,33 @@ func ListServices(c *gin.Context) {
 // @Produce     json
 // @Param       Authorization header string true "Bearer token"
 // @Param       versions query bool false "Return related versions"
+// @Param       If-None-Match header string false "ETag of a previously fetched representation"
 // @Success     200  {object}  ServiceOutput
 // @Success     200  {object}  GetServiceWithVersionsOutput
+// @Failure     default  {object}  middleware.Problem
 // @Router      /service/{id} [get]
 //
 // GetService returns the requested Service based on the 'id' query parameter.
 // If the 'versions' query parameter is present and set to 'true', the Version
 // objects for that Service are also present in the response.
+// The response contains an ETag header, which can be sent back in the If-None-Match
+// header to get an empty 304 response if the Service hasn't been modified since.
 func GetService(c *gin.Context) {
 	svcIdStr := c.Param("id")
 	svcId, err := strconv.Atoi(svcIdStr)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("invalid service id: %s", svcIdStr)})
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
 		return
 	}
 
 	uID, ok := c.Get("userID")
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "unable to fetch user details"})
+		c.Error(errUserDetails)
 		return
 	}
 	userID, ok := uID.(uint)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "unable to fetch user details"})
+		c.Error(errUserDetails)
 		return
 	}
 
@@ -122,29 +142,26 @@ func GetService(c *gin.Context) {
 }
 
 func getService(c *gin.Context, svcID, userID uint) {
-	svc, err := models.GetService(svcID, userID)
+	svc, err := models.GetService(c.Request.Context(), svcID, userID)
 	if err != nil {
-		if errors.Is(err, models.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("unable to fetch service: %s", err.Error())})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": fmt.Sprintf("unable to fetch service: %s", err.Error())})
-		}
+		c.Error(fmt.Errorf("unable to fetch service: %w", err))
 		return
 	}
 
+	c.Header("ETag", serviceETag(svc.RowVersion, false))
+	if notModified(c, serviceETag(svc.RowVersion, false)) {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, ServiceOutput{
 		Data: *svc,
 	})
 }
 
 func getServiceWithVersions(c *gin.Context, svcID, userID uint) {
-	output, err := models.GetServiceWithVersions(svcID, userID)
+	output, err := models.GetServiceWithVersions(c.Request.Context(), svcID, userID)
 	if err != nil {
-		if errors.Is(err, models.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("unable to fetch service: %s", err.Error())})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": fmt.Sprintf("unable to fetch service: %s", err.Error())})
-		}
+		c.Error(fmt.Errorf("unable to fetch service: %w", err))
 		return
 	}
 
@@ -154,9 +171,19 @@ func getServiceWithVersions(c *gin.Context, svcID, userID uint) {
 			CreatedAt: output[0].ServiceCreatedAt,
 			UpdatedAt: output[0].ServiceUpdatedAt,
 		},
-		Name:        output[0].Name,
-		Description: output[0].Description,
-		UserID:      int(userID),
+		Name:          output[0].Name,
+		Slug:          output[0].Slug,
+		Description:   output[0].Description,
+		UserID:        int(userID),
+		Owner:         output[0].Owner,
+		Tags:          output[0].Tags,
+		Lifecycle:     output[0].Lifecycle,
+		Tier:          output[0].Tier,
+		RepositoryURL: output[0].RepositoryURL,
+		Links:         output[0].Links,
+		Contacts:      output[0].Contacts,
+		CustomFields:  output[0].CustomFields,
+		RowVersion:    output[0].RowVersion,
 	}
 	var versions []models.Version
 	for _, val := range output {
@@ -168,10 +195,16 @@ func getServiceWithVersions(c *gin.Context, svcID, userID uint) {
 			},
 			Version:   val.Version,
 			Changelog: val.Changelog,
+			Release:   val.Release,
 			ServiceID: int(svcID),
 		})
 	}
 
+	c.Header("ETag", serviceETag(svc.RowVersion, true))
+	if notModified(c, serviceETag(svc.RowVersion, true)) {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, GetServiceWithVersionsOutput{
 		Data: ServiceWithVersions{
 			Service:  svc,
@@ -180,6 +213,86 @@ func getServiceWithVersions(c *gin.Context, svcID, userID uint) {
 	})
 }
 
+// GetServiceBySlug godoc
+// @Summary     Get a service by its slug.
+// @Description Slugs never change, even if the service is renamed.
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Param       versions query bool false "Return related versions"
+// @Param       If-None-Match header string false "ETag of a previously fetched representation"
+// @Success     200  {object}  ServiceOutput
+// @Success     200  {object}  GetServiceWithVersionsOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /services/by-slug/{slug} [get]
+//
+// GetServiceBySlug returns the requested Service based on the 'slug' path parameter,
+// in the same way as GetService.
+func GetServiceBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	svc, err := models.GetServiceBySlug(c.Request.Context(), slug, userID)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to fetch service: %w", err))
+		return
+	}
+
+	withVersions := c.Query("versions")
+	if withVersions == "true" {
+		getServiceWithVersions(c, svc.ID, userID)
+	} else {
+		getService(c, svc.ID, userID)
+	}
+}
+
+// ListServiceRenames godoc
+// @Summary Get the rename history of a service.
+// @Produce json
+// @Param   Authorization header string true "Bearer token"
+// @Success 200  {object}  ListServiceRenamesOutput
+// @Failure default  {object}  middleware.Problem
+// @Router  /service/{id}/renames [get]
+//
+// ListServiceRenames returns the renames of the provided Service, oldest first.
+func ListServiceRenames(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	renames, err := models.ListServiceRenames(c.Request.Context(), uint(svcId), userID)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to list service renames: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, ListServiceRenamesOutput{
+		Data: renames,
+	})
+}
+
 // CreateService godoc
 // @Summary Create a service.
 // @Accept  json
@@ -187,6 +300,7 @@ func getServiceWithVersions(c *gin.Context, svcID, userID uint) {
 // @Param   Authorization header string true "Bearer token"
 // @Param   service body   models.CreateServiceInput true  "Service JSON"
 // @Success 201  {object}  ServiceOutput
+// @Failure default  {object}  middleware.Problem
 // @Router  /service [post]
 //
 // CreateService creates a Service for the authenticated user.
@@ -194,25 +308,25 @@ func getServiceWithVersions(c *gin.Context, svcID, userID uint) {
 func CreateService(c *gin.Context) {
 	uID, ok := c.Get("userID")
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "unable to fetch user details"})
+		c.Error(errUserDetails)
 		return
 	}
 	userID, ok := uID.(uint)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "unable to fetch user details"})
+		c.Error(errUserDetails)
 		return
 	}
 
 	var input models.CreateServiceInput
 	input.UserID = userID
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("invalid service input: %s", err.Error())})
+		c.Error(invalidInput("invalid service input", err))
 		return
 	}
 
-	service, err := models.CreateService(input)
+	service, err := models.CreateService(c.Request.Context(), input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": fmt.Sprintf("unable to create service: %s", err.Error())})
+		c.Error(fmt.Errorf("unable to create service: %w", err))
 		return
 	}
 
@@ -222,53 +336,89 @@ func CreateService(c *gin.Context) {
 }
 
 // UpdateService godoc
-// @Summary Update a service
-// @Accept  json
-// @Produce json
-// @Param   Authorization header string true "Bearer token"
-// @Param   version body   models.UpdateServiceInput true  "Service update JSON"
-// @Success 201  {object}  ServiceOutput
-// @Router  /service/{id} [patch]
+// @Summary     Update a service
+// @Description With 'application/json', only the fields present in the body are updated.
+// @Description 'application/merge-patch+json' (RFC 7396) and 'application/json-patch+json' (RFC 6902) are applied
+// @Description to the service's name, description, owner and tags; a null in a merge patch clears the field.
+// @Accept      json
+// @Accept      application/merge-patch+json
+// @Accept      application/json-patch+json
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Param       If-Match header string false "ETag of the service being updated"
+// @Param       version body   models.UpdateServiceInput true  "Service update JSON"
+// @Success     200  {object}  ServiceOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /service/{id} [patch]
 //
 // UpdateService updates the Service according to the provided input.
+// If the If-Match header is present, the update is only applied if it matches
+// the Service's current ETag.
 func UpdateService(c *gin.Context) {
 	svcIdStr := c.Param("id")
 	svcId, err := strconv.Atoi(svcIdStr)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("invalid service id: %s", svcIdStr)})
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
 		return
 	}
 
 	uID, ok := c.Get("userID")
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "unable to fetch user details"})
+		c.Error(errUserDetails)
 		return
 	}
 	userID, ok := uID.(uint)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "unable to fetch user details"})
+		c.Error(errUserDetails)
 		return
 	}
 
-	var input models.UpdateServiceInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("invalid service update input: %s", err.Error())})
-		return
+	var expectedRowVersions []uint
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		rowVersions, wildcard := parseETags(ifMatch, false)
+		if !wildcard {
+			if len(rowVersions) == 0 {
+				c.Error(fmt.Errorf("unable to update service: %w", models.ErrRowVersionMismatch))
+				return
+			}
+			expectedRowVersions = rowVersions
+		}
 	}
-	if input.Name == "" && input.Description == "" {
-		c.JSON(http.StatusNoContent, gin.H{"message": fmt.Sprintf("empty service update input")})
+
+	var svc *models.Service
+	switch c.ContentType() {
+	case mergePatchContentType, jsonPatchContentType:
+		patch, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Error(invalidInput("invalid service update input", err))
+			return
+		}
+		svc, err = models.PatchService(c.Request.Context(), uint(svcId), userID, expectedRowVersions, func(current models.Service) (models.UpdateServiceInput, error) {
+			return applyServicePatch(current, c.ContentType(), patch)
+		})
+	case binding.MIMEJSON, "":
+		var input models.UpdateServiceInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(invalidInput("invalid service update input", err))
+			return
+		}
+		if input.IsEmpty() {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		input.ExpectedRowVersions = expectedRowVersions
+		svc, err = models.UpdateService(c.Request.Context(), input, uint(svcId), userID)
+	default:
+		c.Header("Accept-Patch", acceptPatch)
+		c.Error(middleware.NewProblem(http.StatusUnsupportedMediaType, "unsupported_media_type", fmt.Sprintf("unsupported content type: %s", c.ContentType())))
 		return
 	}
 
-	svc, err := models.UpdateService(input, uint(svcId), userID)
 	if err != nil {
-		if errors.Is(err, models.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("unable to update service: %s", err.Error())})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": fmt.Sprintf("unable to update service: %s", err.Error())})
-		}
+		c.Error(fmt.Errorf("unable to update service: %w", err))
 		return
 	}
+	c.Header("ETag", serviceETag(svc.RowVersion, false))
 	c.JSON(http.StatusOK, ServiceOutput{
 		Data: *svc,
 	})
@@ -281,6 +431,7 @@ func UpdateService(c *gin.Context) {
 // @Param   Authorization header string true "Bearer token"
 // @Param   version body   models.CreateVersionInput true  "Version JSON"
 // @Success 201  {object}  CreateVersionOutput
+// @Failure default  {object}  middleware.Problem
 // @Router  /service/{id}/version [post]
 //
 // CreateVersion creates a new Version for the provided Service.
@@ -290,18 +441,18 @@ func CreateVersion(c *gin.Context) {
 	svcIdStr := c.Param("id")
 	svcId, err := strconv.Atoi(svcIdStr)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("invalid service id: %s", svcIdStr)})
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
 		return
 	}
 
 	uID, ok := c.Get("userID")
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "unable to fetch user details"})
+		c.Error(errUserDetails)
 		return
 	}
 	userID, ok := uID.(uint)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "unable to fetch user details"})
+		c.Error(errUserDetails)
 		return
 	}
 
@@ -310,17 +461,13 @@ func CreateVersion(c *gin.Context) {
 	input.ServiceID = svcId
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("invalid version input: %s", err.Error())})
+		c.Error(invalidInput("invalid version input", err))
 		return
 	}
 
-	version, err := models.CreateVersion(input)
+	version, err := models.CreateVersion(c.Request.Context(), input)
 	if err != nil {
-		if errors.Is(err, models.ErrRecordNotFound) || errors.Is(err, models.ErrUniqueConstraintViolation) {
-			c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("unable to create version: %s", err.Error())})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": fmt.Sprintf("unable to create version %s", err.Error())})
-		}
+		c.Error(fmt.Errorf("unable to create version: %w", err))
 		return
 	}
 
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/aryan9600/service-catalog/internal/auth"
+	"github.com/aryan9600/service-catalog/internal/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -209,6 +210,255 @@ func TestGetService(t *testing.T) {
 	}
 }
 
+func TestUpdateService(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		assertFunc  func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "updating a service with an empty description clears it",
+			contentType: "application/json",
+			body:        `{"description": ""}`,
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, w.Code)
+				var response ServiceOutput
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Equal(t, "observability", response.Data.Name)
+				assert.Equal(t, "", response.Data.Description)
+			},
+		},
+		{
+			name:        "updating a service with an empty input returns a 204",
+			contentType: "application/json",
+			body:        `{}`,
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 204, w.Code)
+				assert.Empty(t, w.Body.String())
+			},
+		},
+		{
+			name:        "updating a service with a merge patch",
+			contentType: "application/merge-patch+json",
+			body:        `{"description": "logs", "owner": null, "tags": ["o11y"]}`,
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, w.Code)
+				var response ServiceOutput
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Equal(t, "observability", response.Data.Name)
+				assert.Equal(t, "logs", response.Data.Description)
+				assert.Equal(t, "", response.Data.Owner)
+				assert.Equal(t, []string{"o11y"}, []string(response.Data.Tags))
+			},
+		},
+		{
+			name:        "updating a service with a merge patch which clears its name returns a 422",
+			contentType: "application/merge-patch+json",
+			body:        `{"name": null}`,
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 422, w.Code)
+			},
+		},
+		{
+			name:        "updating a service with a json patch",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "test", "path": "/description", "value": "logs"}, {"op": "add", "path": "/tags/-", "value": "metrics"}]`,
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, w.Code)
+				var response ServiceOutput
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				assert.Equal(t, []string{"o11y", "metrics"}, []string(response.Data.Tags))
+			},
+		},
+		{
+			name:        "updating a service with a json patch whose test fails returns a 409",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "test", "path": "/description", "value": "traces"}, {"op": "remove", "path": "/tags"}]`,
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 409, w.Code)
+			},
+		},
+		{
+			name:        "updating a service with an unsupported content type returns a 415",
+			contentType: "text/plain",
+			body:        `description=logs`,
+			assertFunc: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 415, w.Code)
+				assert.NotEmpty(t, w.Header().Get("Accept-Patch"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(t, "PATCH", "/services/4", tt.body, 2, http.Header{"Content-Type": {tt.contentType}})
+			tt.assertFunc(t, w)
+		})
+	}
+}
+
+func TestServiceConditionalRequests(t *testing.T) {
+	w := doRequest(t, "GET", "/services/4", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	w = doRequest(t, "GET", "/services/4", "", 2, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, 304, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = doRequest(t, "PATCH", "/services/4", `{"description": "logs, metrics and traces"}`, 2, http.Header{"If-Match": {etag}})
+	assert.Equal(t, 200, w.Code)
+	newETag := w.Header().Get("ETag")
+	assert.NotEqual(t, etag, newETag)
+
+	w = doRequest(t, "PATCH", "/services/4", `{"description": "logs"}`, 2, http.Header{"If-Match": {etag}})
+	assert.Equal(t, 412, w.Code)
+	assert.Contains(t, w.Body.String(), models.ErrRowVersionMismatch.Error())
+
+	// The representation with versions has a tag of its own, which If-Match accepts as well.
+	w = doRequest(t, "GET", "/services/4?versions=true", "", 2, http.Header{"If-None-Match": {newETag}})
+	assert.Equal(t, 200, w.Code)
+	versionsETag := w.Header().Get("ETag")
+	assert.NotEmpty(t, versionsETag)
+	assert.NotEqual(t, newETag, versionsETag)
+
+	w = doRequest(t, "GET", "/services/4?versions=true", "", 2, http.Header{"If-None-Match": {versionsETag}})
+	assert.Equal(t, 304, w.Code)
+
+	w = doRequest(t, "PATCH", "/services/4", `{"description": "logs, metrics and traces"}`, 2, http.Header{"If-Match": {versionsETag}})
+	assert.Equal(t, 200, w.Code)
+	newETag = w.Header().Get("ETag")
+
+	w = doRequest(t, "PATCH", "/services/999", `{"description": "logs"}`, 2, http.Header{"If-Match": {newETag}})
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestServiceSlugs(t *testing.T) {
+	w := doRequest(t, "POST", "/services", `{"name": "Payments API"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var response ServiceOutput
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Equal(t, "payments-api", response.Data.Slug)
+	svcPath := fmt.Sprintf("/services/%d", response.Data.ID)
+
+	w = doRequest(t, "POST", "/services", `{"name": "Payments API"}`, 2, nil)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), models.ErrServiceNameTaken.Code)
+
+	w = doRequest(t, "POST", "/services", `{"name": "payments", "slug": "Payments!"}`, 2, nil)
+	assert.Equal(t, 400, w.Code)
+
+	w = doRequest(t, "GET", "/services/by-slug/payments-api", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+
+	// Renaming a service doesn't change its slug, so links keep working.
+	w = doRequest(t, "PATCH", svcPath, `{"name": "Payments Gateway"}`, 2, nil)
+	assert.Equal(t, 200, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Equal(t, "Payments Gateway", response.Data.Name)
+	assert.Equal(t, "payments-api", response.Data.Slug)
+
+	w = doRequest(t, "GET", "/services/by-slug/payments-api", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+
+	// The old name is free again, but not the slug derived from it.
+	w = doRequest(t, "POST", "/services", `{"name": "Payments API"}`, 2, nil)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), models.ErrServiceSlugTaken.Code)
+
+	w = doRequest(t, "GET", svcPath+"/renames", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	var renames ListServiceRenamesOutput
+	err = json.Unmarshal(w.Body.Bytes(), &renames)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.Len(t, renames.Data, 1) {
+		assert.Equal(t, "Payments API", renames.Data[0].OldName)
+		assert.Equal(t, "Payments Gateway", renames.Data[0].NewName)
+	}
+
+	w = doRequest(t, "GET", "/services/by-slug/unknown", "", 2, nil)
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestServiceMetadata(t *testing.T) {
+	listNames := func(query string) []string {
+		w := doRequest(t, "GET", "/services?"+query, "", 2, nil)
+		assert.Equal(t, 200, w.Code)
+		var response ListServicesOutput
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		var names []string
+		for _, svc := range response.Data {
+			names = append(names, svc.Name)
+		}
+		return names
+	}
+
+	w := doRequest(t, "POST", "/services", `{
+		"name": "ledger",
+		"lifecycle": "experimental",
+		"tier": 1,
+		"repositoryURL": "https://github.com/example/ledger",
+		"links": {"runbook": "https://wiki.example.com/ledger"},
+		"contacts": [{"type": "slack", "value": "#ledger"}]
+	}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var response ServiceOutput
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Equal(t, models.LifecycleExperimental, response.Data.Lifecycle)
+	assert.Equal(t, 1, response.Data.Tier)
+	assert.Equal(t, "https://wiki.example.com/ledger", response.Data.Links.Runbook)
+	assert.Equal(t, models.Contacts{{Type: "slack", Value: "#ledger"}}, response.Data.Contacts)
+	svcPath := fmt.Sprintf("/services/%d", response.Data.ID)
+
+	assert.Equal(t, []string{"ledger"}, listNames("lifecycle=experimental&tier=1"))
+	assert.Equal(t, []string{"ledger"}, listNames("hasLink=runbook"))
+	assert.Equal(t, []string{"ledger"}, listNames("contactType=slack&contact=%23ledger"))
+	assert.Equal(t, []string{"ledger"}, listNames("repositoryURL=https://github.com/example/ledger"))
+	assert.Empty(t, listNames("lifecycle=deprecated"))
+
+	w = doRequest(t, "GET", "/services?lifecycle=retired", "", 2, nil)
+	assert.Equal(t, 400, w.Code)
+
+	w = doRequest(t, "POST", "/services", `{"name": "ledger-v2", "links": {"docs": "not a url"}}`, 2, nil)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"links.docs"`)
+
+	w = doRequest(t, "PATCH", svcPath, `{"lifecycle": "deprecated", "repositoryURL": ""}`, 2, nil)
+	assert.Equal(t, 200, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Equal(t, models.LifecycleDeprecated, response.Data.Lifecycle)
+	assert.Equal(t, "", response.Data.RepositoryURL)
+	assert.Equal(t, 1, response.Data.Tier)
+}
+
 func addAuthorizationHeader(userID uint, req *http.Request) error {
 	token, err := auth.GenerateToken(userID)
 	if err != nil {
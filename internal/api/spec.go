@@ -0,0 +1,222 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSpecSize is the maximum size of an uploaded API specification.
+const maxSpecSize = 5 << 20
+
+// VersionSpecOutput represents the output returned after attaching an API
+// specification to a Version.
+type VersionSpecOutput struct {
+	Data models.VersionSpec `json:"data"`
+}
+
+// CompatibilityReportOutput represents the output returned while fetching the
+// compatibility report of a Version.
+type CompatibilityReportOutput struct {
+	Data models.CompatibilityReport `json:"data"`
+}
+
+// PutVersionSpecInput represents the query parameters accepted while uploading an API specification.
+type PutVersionSpecInput struct {
+	Format string `form:"format" binding:"omitempty,oneof=openapi asyncapi protobuf"`
+}
+
+// PutVersionSpec godoc
+// @Summary     Attach an API specification to a version.
+// @Description The body must be an OpenAPI 2/3 or AsyncAPI document in JSON or YAML, or a protobuf definition.
+// @Description The format is detected from the content and the spec is validated before being stored.
+// @Description OpenAPI specs are compared with the spec of the previous version and the result is returned as compatibility.
+// @Accept      json,application/yaml,text/x-protobuf
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Param       format query string false "Expected format of the spec" Enums(openapi, asyncapi, protobuf)
+// @Success     200  {object}  VersionSpecOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /services/{id}/versions/{version}/spec [put]
+//
+// PutVersionSpec validates the API specification in the request body and attaches
+// it to the provided Version, replacing any existing specification.
+func PutVersionSpec(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	var input PutVersionSpecInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.Error(invalidInput("invalid query parameters", err))
+		return
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSpecSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.Error(middleware.NewProblem(http.StatusRequestEntityTooLarge, "spec_too_large",
+				fmt.Sprintf("spec must be at most %d bytes", maxSpecSize)))
+			return
+		}
+		c.Error(invalidInput("invalid spec", err))
+		return
+	}
+
+	specInput, err := models.ParseSpec(content, input.Format)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	versionSpec, err := models.PutVersionSpec(c.Request.Context(), uint(svcId), userID, c.Param("version"), *specInput)
+	if err != nil {
+		c.Error(fmt.Errorf("unable to store spec: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, VersionSpecOutput{
+		Data: *versionSpec,
+	})
+}
+
+// GetVersionSpec godoc
+// @Summary     Get the API specification of a version.
+// @Description The spec is returned as it was uploaded, with a content type matching its format and encoding.
+// @Produce     application/vnd.oai.openapi+json,application/vnd.oai.openapi,application/json,application/yaml,text/x-protobuf
+// @Param       Authorization header string true "Bearer token"
+// @Param       If-None-Match header string false "ETag of a previously fetched spec"
+// @Success     200  {string}  string
+// @Failure     default  {object}  middleware.Problem
+// @Router      /services/{id}/versions/{version}/spec [get]
+//
+// GetVersionSpec returns the API specification attached to the provided Version.
+// The response contains an ETag header based on the spec's checksum.
+func GetVersionSpec(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	versionSpec, content, err := models.GetVersionSpec(c.Request.Context(), uint(svcId), userID, c.Param("version"))
+	if err != nil {
+		c.Error(fmt.Errorf("unable to fetch spec: %w", err))
+		return
+	}
+	defer content.Close()
+
+	etag := fmt.Sprintf(`"%s"`, versionSpec.SHA256)
+	c.Header("ETag", etag)
+	if notModified(c, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.DataFromReader(http.StatusOK, int64(versionSpec.Size), versionSpec.ContentType, content, nil)
+}
+
+// DeleteVersionSpec godoc
+// @Summary Remove the API specification from a version.
+// @Param   Authorization header string true "Bearer token"
+// @Success 204
+// @Failure default  {object}  middleware.Problem
+// @Router  /services/{id}/versions/{version}/spec [delete]
+//
+// DeleteVersionSpec removes the API specification attached to the provided Version.
+func DeleteVersionSpec(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	if err := models.DeleteVersionSpec(c.Request.Context(), uint(svcId), userID, c.Param("version")); err != nil {
+		c.Error(fmt.Errorf("unable to delete spec: %w", err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetCompatibilityReport godoc
+// @Summary     Get the compatibility report of a version.
+// @Description The report lists the changes between the OpenAPI spec of the version and that of the previous version with an OpenAPI spec.
+// @Produce     json
+// @Param       Authorization header string true "Bearer token"
+// @Success     200  {object}  CompatibilityReportOutput
+// @Failure     default  {object}  middleware.Problem
+// @Router      /services/{id}/versions/{version}/compatibility [get]
+//
+// GetCompatibilityReport returns the breaking and non-breaking changes of the
+// provided Version's OpenAPI spec.
+func GetCompatibilityReport(c *gin.Context) {
+	svcIdStr := c.Param("id")
+	svcId, err := strconv.Atoi(svcIdStr)
+	if err != nil {
+		c.Error(models.NewValidationError(fmt.Sprintf("invalid service id: %s", svcIdStr)))
+		return
+	}
+
+	uID, ok := c.Get("userID")
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+	userID, ok := uID.(uint)
+	if !ok {
+		c.Error(errUserDetails)
+		return
+	}
+
+	report, err := models.GetCompatibilityReport(c.Request.Context(), uint(svcId), userID, c.Param("version"))
+	if err != nil {
+		c.Error(fmt.Errorf("unable to fetch compatibility report: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, CompatibilityReportOutput{
+		Data: *report,
+	})
+}
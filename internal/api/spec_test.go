@@ -0,0 +1,297 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/middleware"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionSpecs(t *testing.T) {
+	openapi := `openapi: 3.0.3
+info:
+  title: Search
+  version: 1.0.0
+paths:
+  /search:
+    get:
+      responses:
+        "200":
+          description: OK
+`
+
+	w := doRequest(t, "POST", "/services", `{"name": "search"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var created ServiceOutput
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	svcPath := fmt.Sprintf("/services/%d", created.Data.ID)
+	w = doRequest(t, "POST", svcPath+"/version", `{"version": "v1"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+
+	w = doRequest(t, "GET", svcPath+"/versions/v1/spec", "", 2, nil)
+	assert.Equal(t, 404, w.Code)
+
+	w = doRequest(t, "PUT", svcPath+"/versions/v2/spec", openapi, 2, http.Header{"Content-Type": {"application/yaml"}})
+	assert.Equal(t, 404, w.Code)
+
+	w = doRequest(t, "PUT", svcPath+"/versions/v1/spec", "openapi: 3.0.3\ninfo: {}\n", 2, http.Header{"Content-Type": {"application/yaml"}})
+	assert.Equal(t, 422, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_spec")
+
+	w = doRequest(t, "PUT", svcPath+"/versions/v1/spec?format=asyncapi", openapi, 2, http.Header{"Content-Type": {"application/yaml"}})
+	assert.Equal(t, 422, w.Code)
+
+	w = doRequest(t, "PUT", svcPath+"/versions/v1/spec", openapi, 2, http.Header{"Content-Type": {"application/yaml"}})
+	assert.Equal(t, 200, w.Code)
+	var output VersionSpecOutput
+	err = json.Unmarshal(w.Body.Bytes(), &output)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Equal(t, "openapi", output.Data.Format)
+	assert.Equal(t, "yaml", output.Data.Encoding)
+	assert.Equal(t, len(openapi), output.Data.Size)
+
+	w = doRequest(t, "GET", svcPath+"/versions/v1/spec", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/vnd.oai.openapi", w.Header().Get("Content-Type"))
+	assert.Equal(t, openapi, w.Body.String())
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, fmt.Sprintf(`"%s"`, output.Data.SHA256), etag)
+
+	w = doRequest(t, "GET", svcPath+"/versions/v1/spec", "", 2, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, 304, w.Code)
+	w = doRequest(t, "GET", svcPath+"/versions/v1/spec", "", 2, http.Header{"If-None-Match": {`"other", W/` + etag}})
+	assert.Equal(t, 304, w.Code)
+	w = doRequest(t, "GET", svcPath+"/versions/v1/spec", "", 2, http.Header{"If-None-Match": {"*"}})
+	assert.Equal(t, 304, w.Code)
+
+	proto := `syntax = "proto3";
+
+service Search {
+  rpc Query(QueryRequest) returns (QueryResponse);
+}
+
+message QueryRequest { string q = 1; }
+message QueryResponse { repeated string results = 1; }
+`
+	w = doRequest(t, "PUT", svcPath+"/versions/v1/spec", proto, 2, http.Header{"Content-Type": {"text/x-protobuf"}})
+	assert.Equal(t, 200, w.Code)
+
+	w = doRequest(t, "GET", svcPath+"/versions/v1/spec", "", 2, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/x-protobuf", w.Header().Get("Content-Type"))
+	assert.Equal(t, proto, w.Body.String())
+
+	// Specs of other users' services can't be accessed.
+	w = doRequest(t, "GET", "/services/1/versions/1.0/spec", "", 2, nil)
+	assert.Equal(t, 404, w.Code)
+
+	w = doRequest(t, "DELETE", svcPath+"/versions/v1/spec", "", 2, nil)
+	assert.Equal(t, 204, w.Code)
+
+	w = doRequest(t, "GET", svcPath+"/versions/v1/spec", "", 2, nil)
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestVersionSpecRollback(t *testing.T) {
+	w := doRequest(t, "POST", "/services", `{"name": "ledger"}`, 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var created ServiceOutput
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	spec, err := json.Marshal(`{"openapi": "3.0.3", "info": {"title": "Ledger", "version": "1.0.0"}, "paths": {}}`)
+	if err != nil {
+		t.Fatalf("Failed to marshal spec: %v", err)
+	}
+	body := fmt.Sprintf(`{"mode": "atomic", "operations": [
+		{"op": "createVersion", "serviceID": %d, "version": {"version": "v1", "spec": %s}},
+		{"op": "createVersion", "serviceID": 999, "version": {"version": "v1"}}
+	]}`, created.Data.ID, spec)
+	w = doRequest(t, "POST", "/services:batch", body, 2, nil)
+	assert.Equal(t, 404, w.Code)
+
+	// The blob of the spec is deleted along with the rolled back version.
+	var blobs []string
+	filepath.WalkDir(filepath.Join(specDir, "specs", fmt.Sprint(created.Data.ID)), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			blobs = append(blobs, path)
+		}
+		return nil
+	})
+	assert.Empty(t, blobs)
+}
+
+func TestVersionCompatibility(t *testing.T) {
+	models.SetSpecCompatibilityConfig(config.Specs{RejectBreakingChanges: true})
+	t.Cleanup(func() {
+		models.SetSpecCompatibilityConfig(config.Specs{})
+	})
+
+	v1 := `{
+		"openapi": "3.0.3",
+		"info": {"title": "Inventory", "version": "1.0.0"},
+		"paths": {
+			"/items": {
+				"get": {
+					"parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer"}}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			},
+			"/items/{id}": {
+				"delete": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {"204": {"description": "Deleted"}}
+				}
+			}
+		}
+	}`
+	// Adds an operation, which isn't breaking.
+	v1_1 := `{
+		"openapi": "3.0.3",
+		"info": {"title": "Inventory", "version": "1.1.0"},
+		"paths": {
+			"/items": {
+				"get": {
+					"parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer"}}],
+					"responses": {"200": {"description": "OK"}}
+				},
+				"post": {"responses": {"201": {"description": "Created"}}}
+			},
+			"/items/{id}": {
+				"delete": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {"204": {"description": "Deleted"}}
+				}
+			}
+		}
+	}`
+	// Removes an operation and makes a parameter required.
+	v2 := `{
+		"openapi": "3.0.3",
+		"info": {"title": "Inventory", "version": "2.0.0"},
+		"paths": {
+			"/items": {
+				"get": {
+					"parameters": [{"name": "limit", "in": "query", "required": true, "schema": {"type": "integer"}}],
+					"responses": {"200": {"description": "OK"}}
+				},
+				"post": {"responses": {"201": {"description": "Created"}}}
+			}
+		}
+	}`
+
+	w := doRequest(t, "POST", "/services", jsonBody(t, map[string]string{"name": "inventory"}), 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var created ServiceOutput
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	svcPath := fmt.Sprintf("/services/%d", created.Data.ID)
+
+	w = doRequest(t, "POST", svcPath+"/version", jsonBody(t, map[string]string{"version": "1.0.0", "spec": "openapi: 3.0.3"}), 2, nil)
+	assert.Equal(t, 422, w.Code)
+
+	w = doRequest(t, "POST", svcPath+"/version", jsonBody(t, map[string]string{"version": "1.0.0", "spec": v1}), 2, nil)
+	assert.Equal(t, 201, w.Code)
+	var version CreateVersionOutput
+	err = json.Unmarshal(w.Body.Bytes(), &version)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.NotNil(t, version.Data.Spec) {
+		assert.Equal(t, "openapi", version.Data.Spec.Format)
+		assert.Nil(t, version.Data.Spec.Compatibility)
+	}
+
+	w = doRequest(t, "GET", svcPath+"/versions/1.0.0/compatibility", "", 2, nil)
+	assert.Equal(t, 404, w.Code)
+
+	w = doRequest(t, "POST", svcPath+"/version", jsonBody(t, map[string]string{"version": "1.1.0", "spec": v1_1}), 2, nil)
+	assert.Equal(t, 201, w.Code)
+
+	w = doRequest(t, "GET", svcPath+"/versions/1.1.0/compatibility", "", 2, nil)
+	assert.Equal(t, 200, w.Code)
+	var report CompatibilityReportOutput
+	err = json.Unmarshal(w.Body.Bytes(), &report)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Equal(t, "1.0.0", report.Data.BaseVersion)
+	assert.False(t, report.Data.Breaking)
+	if assert.Len(t, report.Data.Changes, 1) {
+		assert.Equal(t, "operation_added", report.Data.Changes[0].Kind)
+	}
+
+	// Breaking changes require a major version bump.
+	w = doRequest(t, "POST", svcPath+"/version", jsonBody(t, map[string]string{"version": "1.2.0", "spec": v2}), 2, nil)
+	assert.Equal(t, 422, w.Code)
+	var problem middleware.Problem
+	err = json.Unmarshal(w.Body.Bytes(), &problem)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Equal(t, "breaking_change", problem.Code)
+	assert.Len(t, problem.Errors, 2)
+
+	w = doRequest(t, "GET", svcPath+"/versions/1.2.0/compatibility", "", 2, nil)
+	assert.Equal(t, 404, w.Code)
+
+	// Without semantic versions, it's unknown whether the version is a major bump.
+	w = doRequest(t, "POST", svcPath+"/version", jsonBody(t, map[string]string{"version": "next", "spec": v2}), 2, nil)
+	assert.Equal(t, 422, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &problem)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	assert.Equal(t, "breaking_change", problem.Code)
+	assert.Contains(t, problem.Detail, "semantic versions")
+
+	w = doRequest(t, "POST", svcPath+"/version", jsonBody(t, map[string]string{"version": "2.0.0", "spec": v2}), 2, nil)
+	assert.Equal(t, 201, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &version)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.NotNil(t, version.Data.Spec) && assert.NotNil(t, version.Data.Spec.Compatibility) {
+		assert.Equal(t, "1.1.0", version.Data.Spec.Compatibility.BaseVersion)
+		assert.True(t, version.Data.Spec.Compatibility.Breaking)
+	}
+
+	// A patch release of an older version is compared with the closest lower version,
+	// not with the one created last.
+	w = doRequest(t, "POST", svcPath+"/version", jsonBody(t, map[string]string{"version": "1.1.1", "spec": v1_1}), 2, nil)
+	assert.Equal(t, 201, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &version)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if assert.NotNil(t, version.Data.Spec) && assert.NotNil(t, version.Data.Spec.Compatibility) {
+		assert.Equal(t, "1.1.0", version.Data.Spec.Compatibility.BaseVersion)
+		assert.False(t, version.Data.Spec.Compatibility.Breaking)
+		assert.Empty(t, version.Data.Spec.Compatibility.Changes)
+	}
+}
+
+// jsonBody returns the JSON encoding of v, as the body of a request.
+func jsonBody(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal body: %v", err)
+	}
+	return string(b)
+}
@@ -1,28 +1,47 @@
 package api
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/aryan9600/service-catalog/internal/auth"
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/metrics"
 	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/aryan9600/service-catalog/internal/storage"
+	"github.com/aryan9600/service-catalog/internal/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
 )
 
 var router *gin.Engine
 
+// specDir is the directory of the filesystem store of API specifications.
+var specDir string
+
 func TestMain(m *testing.M) {
 	if err := godotenv.Load("../../.env.test"); err != nil {
 		panic(err)
 	}
-	if err := models.SetDBConfiguration(); err != nil {
+	cfg, err := config.Load(nil)
+	if err != nil {
 		panic(err)
 	}
+	models.SetDBConfiguration(cfg.Database)
 	if err := models.InitDB(); err != nil {
 		panic(err)
 	}
+	if err := metrics.RegisterDB(models.DB); err != nil {
+		panic(err)
+	}
+	if err := models.DB.Use(tracing.GormPlugin{}); err != nil {
+		panic(err)
+	}
 	if err := models.Migrate("file://../models/migrations", true); err != nil {
 		panic(err)
 	}
@@ -30,15 +49,26 @@ func TestMain(m *testing.M) {
 	if err := models.Migrate("file://../models/migrations", false); err != nil {
 		panic(err)
 	}
-	if err := auth.SetTokenGenerationConfig(); err != nil {
+	auth.SetTokenGenerationConfig(cfg.Auth)
+	// Rate limits are tested on their own by TestRateLimit.
+	cfg.RateLimit.Enabled = false
+	SetRateLimitConfig(cfg.RateLimit)
+	specDir, err = os.MkdirTemp("", "specs")
+	if err != nil {
 		panic(err)
 	}
+	specStore, err := storage.NewFilesystemStore(specDir)
+	if err != nil {
+		panic(err)
+	}
+	models.SetSpecStore(specStore)
 
 	populateUsers()
 	populateServicesAndVersions()
 
 	router = NewRouter()
 	code := m.Run()
+	os.RemoveAll(specDir)
 	os.Exit(code)
 }
 
@@ -55,6 +85,7 @@ func populateUsers() {
 		{
 			Username: "user1",
 			Password: pwd1,
+			IsAdmin:  true,
 		},
 		{
 			Username: "user2",
@@ -70,30 +101,35 @@ func populateServicesAndVersions() {
 	services := []models.Service{
 		{
 			Name:        "auth",
+			Slug:        "auth",
 			Description: "authentication and authrorization",
 			Versions:    pq.StringArray{"1.0", "1.1"},
 			UserID:      1,
 		},
 		{
 			Name:        "storage",
+			Slug:        "storage",
 			Description: "durable kv store",
 			Versions:    pq.StringArray{"0.1", "0.2"},
 			UserID:      1,
 		},
 		{
 			Name:        "dns",
+			Slug:        "dns",
 			Description: "domains, subdomains and wildcard domains",
 			Versions:    pq.StringArray{"1", "2"},
 			UserID:      1,
 		},
 		{
 			Name:        "observability",
+			Slug:        "observability",
 			Description: "logging, metrics, profiling",
 			Versions:    pq.StringArray{"v1", "v2"},
 			UserID:      2,
 		},
 		{
 			Name:        "service mesh",
+			Slug:        "service-mesh",
 			Description: "networking, mTLS",
 			Versions:    pq.StringArray{"alpha", "beta"},
 			UserID:      2,
@@ -103,6 +139,13 @@ func populateServicesAndVersions() {
 	if err := models.DB.Table(models.ServiceTableName).Create(&services).Error; err != nil {
 		panic(err)
 	}
+	var slugs []map[string]interface{}
+	for _, svc := range services {
+		slugs = append(slugs, map[string]interface{}{"service_id": svc.ID, "user_id": svc.UserID, "slug": svc.Slug})
+	}
+	if err := models.DB.Table(models.ServiceSlugTableName).Create(&slugs).Error; err != nil {
+		panic(err)
+	}
 	var versions []models.Version
 	for _, svc := range services {
 		for _, v := range svc.Versions {
@@ -117,3 +160,36 @@ func populateServicesAndVersions() {
 		panic(err)
 	}
 }
+
+// newRequest returns a request for the router, authenticated as the user with a
+// bearer token unless userID is 0. A body is sent as JSON unless the header sets
+// another Content-Type.
+func newRequest(t *testing.T, method, path, body string, userID uint, header http.Header) *http.Request {
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range header {
+		req.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	if userID != 0 {
+		err = addAuthorizationHeader(userID, req)
+		assert.NoError(t, err)
+	}
+	return req
+}
+
+// serve serves the request with the handler and returns the recorded response.
+func serve(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// doRequest serves a request of the user to the router, see newRequest.
+func doRequest(t *testing.T, method, path, body string, userID uint, header http.Header) *httptest.ResponseRecorder {
+	return serve(router, newRequest(t, method, path, body, userID, header))
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/auth"
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCertificate is a certificate along with its key, for signing other
+// certificates and for TLS.
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (c testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	if err != nil {
+		t.Fatalf("Failed to load certificate: %v", err)
+	}
+	return cert
+}
+
+// newTestCertificate returns a certificate of the template signed by the parent, or
+// self-signed if the parent is nil.
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	return testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func newServerCertificate(t *testing.T, ca testCertificate, commonName string) testCertificate {
+	return newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+}
+
+func newClientCertificate(t *testing.T, ca testCertificate, commonName string, uris ...string) testCertificate {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		if err != nil {
+			t.Fatalf("Failed to parse URI: %v", err)
+		}
+		template.URIs = append(template.URIs, u)
+	}
+	return newTestCertificate(t, template, &ca)
+}
+
+func writeFile(t *testing.T, path string, content []byte) {
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestTLS(t *testing.T) {
+	previous := serverConfig
+	t.Cleanup(func() {
+		serverConfig = previous
+		auth.SetClientCertificateConfig(previous.TLS)
+	})
+
+	ca := newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	otherCA := newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "other CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	serverCert := newServerCertificate(t, ca, "catalog")
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	caFile := filepath.Join(dir, "ca.crt")
+	writeFile(t, certFile, serverCert.certPEM)
+	writeFile(t, keyFile, serverCert.keyPEM)
+	writeFile(t, caFile, ca.certPEM)
+
+	cfg := config.Default().Server
+	cfg.TLS = config.TLS{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		MinVersion:     "1.2",
+		ReloadInterval: 10 * time.Millisecond,
+		ClientAuth:     "optional",
+		ClientCAFile:   caFile,
+		ClientIdentities: map[string]string{
+			"uri:spiffe://example.com/ci": "user1",
+			"cn:deployer":                 "user2",
+		},
+	}
+	SetServerConfig(cfg)
+	auth.SetClientCertificateConfig(cfg.TLS)
+
+	server, err := NewServer(router)
+	assert.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go server.ServeTLS(ln, "", "")
+	t.Cleanup(func() {
+		server.Close()
+	})
+	baseURL := "https://" + ln.Addr().String()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.cert)
+	get := func(t *testing.T, path string, clientCert *testCertificate) (*http.Response, error) {
+		tlsConfig := &tls.Config{RootCAs: rootCAs}
+		if clientCert != nil {
+			cert := clientCert.tlsCertificate(t)
+			// Unlike Certificates, this sends the certificate even if it isn't signed by
+			// one of the CAs the server accepts.
+			tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return &cert, nil
+			}
+		}
+		// Every request has a connection of its own, so that it's handshaked with the
+		// current certificate.
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true}}
+		resp, err := client.Get(baseURL + path)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	ci := newClientCertificate(t, ca, "ci", "spiffe://example.com/ci")
+	deployer := newClientCertificate(t, ca, "deployer")
+	unknown := newClientCertificate(t, ca, "unknown", "spiffe://example.com/unknown")
+	untrusted := newClientCertificate(t, otherCA, "ci", "spiffe://example.com/ci")
+
+	tests := []struct {
+		name       string
+		path       string
+		clientCert *testCertificate
+		code       int
+	}{
+		{name: "without client certificate", path: "/healthz", code: 200},
+		{name: "without credentials", path: "/services", code: 401},
+		{name: "client certificate mapped by URI", path: "/services", clientCert: &ci, code: 200},
+		{name: "client certificate mapped by CN", path: "/services", clientCert: &deployer, code: 200},
+		{name: "client certificate which isn't mapped", path: "/services", clientCert: &unknown, code: 401},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := get(t, tt.path, tt.clientCert)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.code, resp.StatusCode)
+			assert.Equal(t, "max-age=31536000", resp.Header.Get("Strict-Transport-Security"))
+		})
+	}
+
+	t.Run("client certificate of another CA", func(t *testing.T) {
+		_, err := get(t, "/healthz", &untrusted)
+		assert.Error(t, err)
+	})
+
+	t.Run("renewed certificate", func(t *testing.T) {
+		renewed := newServerCertificate(t, ca, "renewed catalog")
+		writeFile(t, certFile, renewed.certPEM)
+		writeFile(t, keyFile, renewed.keyPEM)
+		// Make sure the modification times change, even on filesystems with a coarse resolution.
+		later := time.Now().Add(time.Minute)
+		assert.NoError(t, os.Chtimes(certFile, later, later))
+		assert.NoError(t, os.Chtimes(keyFile, later, later))
+		time.Sleep(20 * time.Millisecond)
+
+		resp, err := get(t, "/healthz", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "renewed catalog", resp.TLS.PeerCertificates[0].Subject.CommonName)
+	})
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	w := doRequest(t, "GET", "/services/1?versions=true", "", 1, http.Header{
+		"traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	})
+	assert.Equal(t, 200, w.Code)
+
+	spans := exporter.GetSpans()
+	var server *tracetest.SpanStub
+	for i, span := range spans {
+		if span.Name == "GET /services/:id" {
+			server = &spans[i]
+		}
+	}
+	if !assert.NotNil(t, server) {
+		return
+	}
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+
+	attrs := make(map[string]string)
+	for _, attr := range server.Attributes {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.Equal(t, "/services/:id", attrs["http.route"])
+	assert.Equal(t, "200", attrs["http.response.status_code"])
+	assert.Equal(t, "1", attrs["enduser.id"])
+
+	queries := 0
+	for _, span := range spans {
+		if !strings.HasPrefix(span.Name, "gorm.") {
+			continue
+		}
+		queries++
+		assert.Equal(t, server.SpanContext.SpanID(), span.Parent.SpanID())
+		for _, attr := range span.Attributes {
+			if attr.Key == "db.statement" {
+				assert.NotContains(t, attr.Value.AsString(), "'")
+			}
+		}
+	}
+	assert.NotZero(t, queries)
+
+	t.Run("listing services", func(t *testing.T) {
+		exporter.Reset()
+		w := doRequest(t, "GET", "/services?name=auth", "", 1, nil)
+		assert.Equal(t, 200, w.Code)
+
+		spans := exporter.GetSpans()
+		var server *tracetest.SpanStub
+		for i, span := range spans {
+			if span.Name == "GET /services" {
+				server = &spans[i]
+			}
+		}
+		if !assert.NotNil(t, server) {
+			return
+		}
+		queries := 0
+		for _, span := range spans {
+			if strings.HasPrefix(span.Name, "gorm.") {
+				queries++
+				assert.Equal(t, server.SpanContext.SpanID(), span.Parent.SpanID())
+			}
+		}
+		assert.NotZero(t, queries)
+	})
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/x509"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+)
+
+var clientIdentities map[string]string
+
+// SetClientCertificateConfig sets which identities of client certificates
+// authenticate as which users.
+func SetClientCertificateConfig(cfg config.TLS) {
+	clientIdentities = cfg.ClientIdentities
+}
+
+// CertificateIdentities returns the identities of the certificate which can be
+// mapped to users: its URI, DNS and email SANs, followed by its CN, like
+// 'uri:spiffe://example.com/ci' or 'cn:deployer'.
+func CertificateIdentities(cert *x509.Certificate) []string {
+	var identities []string
+	for _, uri := range cert.URIs {
+		identities = append(identities, "uri:"+uri.String())
+	}
+	for _, name := range cert.DNSNames {
+		identities = append(identities, "dns:"+name)
+	}
+	for _, email := range cert.EmailAddresses {
+		identities = append(identities, "email:"+email)
+	}
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, "cn:"+cert.Subject.CommonName)
+	}
+	return identities
+}
+
+// UsernameFromCertificate returns the username the first mapped identity of the
+// certificate authenticates as, and false if none of its identities is mapped.
+func UsernameFromCertificate(cert *x509.Certificate) (string, bool) {
+	for _, identity := range CertificateIdentities(cert) {
+		if username, ok := clientIdentities[identity]; ok {
+			return username, true
+		}
+	}
+	return "", false
+}
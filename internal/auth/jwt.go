@@ -2,10 +2,9 @@ package auth
 
 import (
 	"fmt"
-	"os"
-	"strconv"
 	"time"
 
+	"github.com/aryan9600/service-catalog/internal/config"
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
@@ -14,25 +13,18 @@ var (
 	signingKey    []byte
 )
 
-// SetTokenGenerationConfig reads the token generation configuration from env vars.
+// SetTokenGenerationConfig sets the token generation configuration.
 // This is NEEDS to be called before using the below JWT methods.
-func SetTokenGenerationConfig() error {
-	tokenLifespanStr := os.Getenv("TOKEN_HOUR_LIFESPAN")
-	var err error
-	if tokenLifespanStr == "" {
-		err = fmt.Errorf("unable to read env var TOKEN_HOUR_LIFESPAN")
-		return err
-	}
-	tokenLifespan, err = strconv.Atoi(tokenLifespanStr)
-	if err != nil {
-		err = fmt.Errorf("invalid value for env var TOKEN_HOUR_LIFESPAN: %s; must be an integer", tokenLifespanStr)
-		return err
-	}
+func SetTokenGenerationConfig(cfg config.Auth) {
+	tokenLifespan = cfg.TokenHourLifespan
+	signingKey = []byte(cfg.JWTSigningKey)
+}
 
-	signingKey = []byte(os.Getenv("JWT_SIGNING_KEY"))
+// CheckSigningKey returns an error if no signing key is configured, i.e. if
+// SetTokenGenerationConfig wasn't called successfully.
+func CheckSigningKey() error {
 	if len(signingKey) == 0 {
-		err = fmt.Errorf("unable to read env var JWT_SIGNING_KEY")
-		return err
+		return fmt.Errorf("JWT signing key is not configured")
 	}
 	return nil
 }
@@ -0,0 +1,163 @@
+package backstage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aryan9600/service-catalog/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	APIVersion    = "backstage.io/v1alpha1"
+	KindComponent = "Component"
+
+	// VersionsAnnotation holds a comma separated list of versions of the component.
+	VersionsAnnotation = "service-catalog/versions"
+
+	defaultComponentType = "service"
+	defaultLifecycle     = "production"
+)
+
+// Entity represents a Backstage catalog entity, as found in catalog-info.yaml files.
+// Only the fields relevant to the service catalog are represented.
+type Entity struct {
+	APIVersion string   `yaml:"apiVersion" json:"apiVersion"`
+	Kind       string   `yaml:"kind" json:"kind"`
+	Metadata   Metadata `yaml:"metadata" json:"metadata"`
+	Spec       Spec     `yaml:"spec" json:"spec"`
+}
+
+// Metadata represents the metadata of a Backstage entity.
+type Metadata struct {
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Tags        []string          `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+}
+
+// Spec represents the spec of a Backstage Component entity.
+type Spec struct {
+	Type      string `yaml:"type,omitempty" json:"type,omitempty"`
+	Lifecycle string `yaml:"lifecycle,omitempty" json:"lifecycle,omitempty"`
+	Owner     string `yaml:"owner,omitempty" json:"owner,omitempty"`
+}
+
+// Decode reads a stream of one or more YAML (or JSON) documents, each containing
+// a single entity. Empty documents are ignored.
+func Decode(r io.Reader) ([]Entity, error) {
+	var entities []Entity
+	decoder := yaml.NewDecoder(r)
+	for i := 0; ; i++ {
+		var entity *Entity
+		err := decoder.Decode(&entity)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode document %d: %w", i, err)
+		}
+		if entity == nil {
+			continue
+		}
+		entities = append(entities, *entity)
+	}
+	return entities, nil
+}
+
+// Encode writes the provided entities as a stream of YAML documents.
+func Encode(w io.Writer, entities []Entity) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	for _, entity := range entities {
+		if err := encoder.Encode(entity); err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
+
+// Validate checks if the entity can be imported as a Service.
+func (e Entity) Validate() error {
+	if e.Metadata.Name == "" {
+		return fmt.Errorf("metadata.name is required")
+	}
+	if len(e.Spec.Owner) > 255 {
+		return fmt.Errorf("spec.owner must be at most 255 characters long")
+	}
+	for _, tag := range e.Metadata.Tags {
+		if len(tag) > 50 {
+			return fmt.Errorf("tag %q must be at most 50 characters long", tag)
+		}
+	}
+	for _, v := range e.Versions() {
+		if len(v) > 50 {
+			return fmt.Errorf("version %q must be at most 50 characters long", v)
+		}
+	}
+	return nil
+}
+
+// Versions returns the versions listed in the entity's versions annotation.
+func (e Entity) Versions() []string {
+	var versions []string
+	for _, v := range strings.Split(e.Metadata.Annotations[VersionsAnnotation], ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// ToImportInput converts the entity into the input required to import it as a Service.
+func (e Entity) ToImportInput() models.ImportServiceInput {
+	return models.ImportServiceInput{
+		Name:        e.Metadata.Name,
+		Description: e.Metadata.Description,
+		Owner:       e.Spec.Owner,
+		Tags:        e.Metadata.Tags,
+		Versions:    e.Versions(),
+		Lifecycle:   e.lifecycle(),
+	}
+}
+
+// lifecycle returns the entity's lifecycle if it's one known to the service catalog.
+// Backstage allows arbitrary lifecycles, which are ignored.
+func (e Entity) lifecycle() string {
+	switch e.Spec.Lifecycle {
+	case models.LifecycleExperimental, models.LifecycleProduction, models.LifecycleDeprecated:
+		return e.Spec.Lifecycle
+	default:
+		return ""
+	}
+}
+
+// FromService converts the provided Service into a Backstage Component entity.
+func FromService(svc models.Service) Entity {
+	entity := Entity{
+		APIVersion: APIVersion,
+		Kind:       KindComponent,
+		Metadata: Metadata{
+			Name:        svc.Name,
+			Description: svc.Description,
+			Tags:        svc.Tags,
+		},
+		Spec: Spec{
+			Type:      defaultComponentType,
+			Lifecycle: svc.Lifecycle,
+			Owner:     svc.Owner,
+		},
+	}
+	if entity.Spec.Lifecycle == "" {
+		entity.Spec.Lifecycle = defaultLifecycle
+	}
+	if len(svc.Versions) > 0 {
+		entity.Metadata.Annotations = map[string]string{
+			VersionsAnnotation: strings.Join(svc.Versions, ","),
+		}
+	}
+	return entity
+}
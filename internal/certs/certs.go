@@ -0,0 +1,164 @@
+// Package certs serves TLS certificates read from files, reloading them when the files
+// change so that renewed certificates are picked up without restarting the server.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/logging"
+)
+
+// Reloader holds a certificate and key, and optionally a pool of CAs of client
+// certificates, read from files. It's safe for concurrent use.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	interval     time.Duration
+
+	mu sync.Mutex
+	// checked is when the files were last checked for changes.
+	checked time.Time
+	// modTimes are the modification times of the files when they were loaded.
+	modTimes  []time.Time
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// NewReloader loads the certificate and key, and the client CAs if clientCAFile
+// isn't empty. The files are checked for changes at most once per interval, when
+// clients connect; an interval of 0 disables reloading.
+func NewReloader(certFile, keyFile, clientCAFile string, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		interval:     interval,
+		checked:      time.Now(),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSConfig returns a copy of base which serves the current certificate, and verifies
+// client certificates with the current client CAs.
+func (r *Reloader) TLSConfig(base *tls.Config) *tls.Config {
+	cfg := base.Clone()
+	cfg.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, clientCAs := r.current(hello.Context())
+		handshake := base.Clone()
+		handshake.Certificates = []tls.Certificate{*cert}
+		handshake.ClientCAs = clientCAs
+		return handshake, nil
+	}
+	// GetConfigForClient takes precedence, but http.Server.ServeTLS requires either
+	// a certificate or GetCertificate.
+	cfg.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		cert, _ := r.current(hello.Context())
+		return cert, nil
+	}
+	return cfg
+}
+
+// current returns the current certificate and client CAs, reloading them first if
+// the files changed.
+func (r *Reloader) current(ctx context.Context) (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.interval > 0 && time.Since(r.checked) >= r.interval {
+		r.checked = time.Now()
+		r.reloadIfChanged(ctx)
+	}
+	return r.cert, r.clientCAs
+}
+
+// reloadIfChanged reloads the files if any of them changed since they were loaded.
+// If they can't be loaded, e.g. because the new key wasn't written yet, the previous
+// ones are kept and loading is retried at the next check.
+func (r *Reloader) reloadIfChanged(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+	modTimes, err := r.stat()
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to check TLS files for changes")
+		return
+	}
+	if !changed(r.modTimes, modTimes) {
+		return
+	}
+	if err := r.load(); err != nil {
+		logger.Warn().Err(err).Msg("unable to reload TLS files, keeping the previous ones")
+		return
+	}
+	logger.Info().
+		Str("subject", r.cert.Leaf.Subject.String()).
+		Time("not_after", r.cert.Leaf.NotAfter).
+		Msg("reloaded TLS certificate")
+}
+
+// load reads all files. The Reloader is only updated if all of them could be read.
+func (r *Reloader) load() error {
+	// The modification times are read first, so that files changing while they're
+	// read get reloaded at the next check.
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("unable to load certificate: %w", err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return fmt.Errorf("unable to parse certificate: %w", err)
+		}
+	}
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		content, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("unable to read client CAs: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(content) {
+			return fmt.Errorf("no certificates found in %s", r.clientCAFile)
+		}
+	}
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
+
+// stat returns the modification times of the files.
+func (r *Reloader) stat() ([]time.Time, error) {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	modTimes := make([]time.Time, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+	return modTimes, nil
+}
+
+func changed(previous, current []time.Time) bool {
+	for i := range current {
+		if !current[i].Equal(previous[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,281 @@
+// Package config loads the configuration of the server from a YAML file, env vars
+// and flags.
+package config
+
+import (
+	"time"
+)
+
+// Config is the configuration of the server. Every setting can be set in the YAML
+// file under the key given by its yaml tags, with the env var given by its env tag
+// and with a flag named after its YAML key, e.g. '-server.port'.
+type Config struct {
+	Server      Server      `yaml:"server"`
+	Database    Database    `yaml:"database"`
+	Auth        Auth        `yaml:"auth"`
+	Log         Log         `yaml:"log"`
+	Services    Services    `yaml:"services"`
+	Specs       Specs       `yaml:"specs"`
+	Deployments Deployments `yaml:"deployments"`
+	Promotion   Promotion   `yaml:"promotion"`
+	HealthCheck HealthCheck `yaml:"healthCheck"`
+	Tracing     Tracing     `yaml:"tracing"`
+	RateLimit   RateLimit   `yaml:"rateLimit"`
+	CORS        CORS        `yaml:"cors"`
+	Security    Security    `yaml:"securityHeaders"`
+}
+
+// Server configures the HTTP server. A zero timeout means no timeout.
+type Server struct {
+	Port              string        `yaml:"port" env:"SERVER_PORT"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" env:"SERVER_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout" env:"SERVER_IDLE_TIMEOUT"`
+	// ShutdownTimeout is how long in-flight requests are given to complete on shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// TrustedProxies are the IPs or CIDR ranges of the reverse proxies in front of the
+	// server, whose X-Forwarded-For and X-Real-IP headers tell the client IP. By default
+	// no proxy is trusted, and the client IP is the one of the connection.
+	TrustedProxies []string `yaml:"trustedProxies" env:"SERVER_TRUSTED_PROXIES"`
+	TLS            TLS      `yaml:"tls" env:"SERVER_TLS_"`
+}
+
+// TLS configures HTTPS, enabled by setting a certificate, and mutual TLS.
+type TLS struct {
+	CertFile string `yaml:"certFile" env:"CERT_FILE"`
+	KeyFile  string `yaml:"keyFile" env:"KEY_FILE"`
+	// MinVersion is the minimum TLS version accepted, '1.2' or '1.3'.
+	MinVersion string `yaml:"minVersion" env:"MIN_VERSION"`
+	// ReloadInterval is how often the files are checked for changes, e.g. after the
+	// certificate was renewed. 0 disables reloading.
+	ReloadInterval time.Duration `yaml:"reloadInterval" env:"RELOAD_INTERVAL"`
+	// ClientAuth is whether clients must present a certificate signed by one of the
+	// CAs of ClientCAFile: 'none', 'optional' or 'require'.
+	ClientAuth   string `yaml:"clientAuth" env:"CLIENT_AUTH"`
+	ClientCAFile string `yaml:"clientCAFile" env:"CLIENT_CA_FILE"`
+	// ClientIdentities maps identities of client certificates, like
+	// 'uri:spiffe://example.com/ci' or 'cn:deployer', to the usernames they
+	// authenticate as. 'dns:' and 'email:' SANs can be mapped too.
+	ClientIdentities map[string]string `yaml:"clientIdentities" env:"CLIENT_IDENTITIES"`
+}
+
+// Database configures the connection to PostgreSQL.
+type Database struct {
+	Host         string `yaml:"host" env:"POSTGRES_HOST"`
+	Port         string `yaml:"port" env:"POSTGRES_PORT"`
+	Name         string `yaml:"name" env:"POSTGRES_DB_NAME"`
+	User         string `yaml:"user" env:"POSTGRES_USER"`
+	Password     string `yaml:"password" env:"POSTGRES_PASSWORD" secret:"true"`
+	PasswordFile string `yaml:"passwordFile" env:"POSTGRES_PASSWORD_FILE"`
+	DisableSSL   bool   `yaml:"disableSSL" env:"POSTGRES_DISABLE_SSL"`
+	// AutoMigrate runs the migrations on startup.
+	AutoMigrate bool `yaml:"autoMigrate" env:"AUTO_MIGRATE"`
+}
+
+// Auth configures the JWTs issued to users.
+type Auth struct {
+	JWTSigningKey     string `yaml:"jwtSigningKey" env:"JWT_SIGNING_KEY" secret:"true"`
+	JWTSigningKeyFile string `yaml:"jwtSigningKeyFile" env:"JWT_SIGNING_KEY_FILE"`
+	TokenHourLifespan int    `yaml:"tokenHourLifespan" env:"TOKEN_HOUR_LIFESPAN"`
+}
+
+// Log configures the logs of the server.
+type Log struct {
+	// Output is one of 'file' (a file rotated according to the settings below),
+	// 'stdout' (JSON lines) or 'console' (human readable lines on stdout).
+	Output string `yaml:"output" env:"LOG_OUTPUT"`
+	// Level is the minimum level of logged lines, one of 'trace', 'debug', 'info',
+	// 'warn', 'error' or 'disabled'. Request headers are logged at 'debug' and below.
+	Level      string `yaml:"level" env:"LOG_LEVEL"`
+	File       string `yaml:"file" env:"LOG_FILE"`
+	MaxSizeMB  int    `yaml:"maxSizeMB" env:"LOG_MAX_SIZE_MB"`
+	MaxBackups int    `yaml:"maxBackups" env:"LOG_MAX_BACKUPS"`
+	MaxAgeDays int    `yaml:"maxAgeDays" env:"LOG_MAX_AGE_DAYS"`
+	Compress   bool   `yaml:"compress" env:"LOG_COMPRESS"`
+	// SampleSuccess logs only one in every SampleSuccess successful requests. Failed
+	// requests are always logged.
+	SampleSuccess int `yaml:"sampleSuccess" env:"LOG_SAMPLE_SUCCESS"`
+	// RedactQueryParams and RedactHeaders are the query parameters and headers whose
+	// value is replaced in logs, matched case-insensitively.
+	RedactQueryParams []string `yaml:"redactQueryParams" env:"LOG_REDACT_QUERY_PARAMS"`
+	RedactHeaders     []string `yaml:"redactHeaders" env:"LOG_REDACT_HEADERS"`
+}
+
+// Services configures the rules for the names of services.
+type Services struct {
+	NameMaxLength int `yaml:"nameMaxLength" env:"SERVICE_NAME_MAX_LENGTH"`
+	// NamePattern, if set, must be matched by the whole name.
+	NamePattern string `yaml:"namePattern" env:"SERVICE_NAME_PATTERN"`
+}
+
+// Specs configures where API specifications are stored and how they are compared.
+type Specs struct {
+	// Storage is either 'filesystem', storing specs under StorageDir, or 'database'.
+	Storage    string `yaml:"storage" env:"SPEC_STORAGE"`
+	StorageDir string `yaml:"storageDir" env:"SPEC_STORAGE_DIR"`
+	// RejectBreakingChanges makes specs with breaking changes require a major version bump.
+	RejectBreakingChanges bool `yaml:"rejectBreakingChanges" env:"SPEC_REJECT_BREAKING_CHANGES"`
+}
+
+// Deployments configures the environments versions are deployed to.
+type Deployments struct {
+	// Environments are ordered from the first to the last stage of a release.
+	Environments []string `yaml:"environments" env:"ENVIRONMENTS"`
+}
+
+// Promotion configures the rules versions must satisfy to be promoted to a stage.
+type Promotion struct {
+	// MinSoakTime is, per stage, how long a version must have been in the previous stage.
+	MinSoakTime map[string]time.Duration `yaml:"minSoakTime" env:"PROMOTION_MIN_SOAK_TIME"`
+	// RequiredApprovals is, per stage, the number of maintainers who must approve a promotion.
+	RequiredApprovals map[string]int `yaml:"requiredApprovals" env:"PROMOTION_REQUIRED_APPROVALS"`
+}
+
+// HealthCheck configures the readiness checks.
+type HealthCheck struct {
+	Timeout time.Duration `yaml:"timeout" env:"HEALTH_CHECK_TIMEOUT"`
+}
+
+// Tracing configures OpenTelemetry tracing.
+type Tracing struct {
+	// Exporter is one of 'none', 'stdout', 'file' (writing to File) or 'otlp'.
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	File     string `yaml:"file" env:"TRACING_FILE"`
+	// SampleRatio is the ratio of traces sampled, from 0 to 1.
+	SampleRatio float64 `yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// RateLimit configures the rate limits of requests, per route group. Requests of
+// authenticated users are limited per user, others per client IP.
+type RateLimit struct {
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// Store is either 'memory', where every replica limits requests on its own, or
+	// 'postgres', where all replicas share the limits.
+	Store string `yaml:"store" env:"RATE_LIMIT_STORE"`
+	// Auth limits the requests to register and log in.
+	Auth RateLimitPolicy `yaml:"auth" env:"RATE_LIMIT_AUTH_"`
+	// Services limits the requests to the services endpoints.
+	Services RateLimitPolicy `yaml:"services" env:"RATE_LIMIT_SERVICES_"`
+	// Catalog limits the requests to import and export the catalog.
+	Catalog RateLimitPolicy `yaml:"catalog" env:"RATE_LIMIT_CATALOG_"`
+	// Default limits the requests to the other authenticated endpoints.
+	Default RateLimitPolicy `yaml:"default" env:"RATE_LIMIT_DEFAULT_"`
+	// ClientIP limits the requests of every client IP to the authenticated endpoints
+	// before they're authenticated, so that requests with invalid credentials are
+	// limited as well.
+	ClientIP RateLimitPolicy `yaml:"clientIP" env:"RATE_LIMIT_CLIENT_IP_"`
+}
+
+// RateLimitPolicy allows bursts of Burst requests, and Requests requests per Period
+// on average.
+type RateLimitPolicy struct {
+	Requests int           `yaml:"requests" env:"REQUESTS"`
+	Period   time.Duration `yaml:"period" env:"PERIOD"`
+	Burst    int           `yaml:"burst" env:"BURST"`
+}
+
+// CORS configures which browser clients of other origins can call the API.
+type CORS struct {
+	// AllowedOrigins are the origins, like 'https://catalog.example.com', allowed to
+	// call the API. '*' allows any origin, and no origin disables CORS.
+	AllowedOrigins []string `yaml:"allowedOrigins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods []string `yaml:"allowedMethods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders []string `yaml:"allowedHeaders" env:"CORS_ALLOWED_HEADERS"`
+	// ExposedHeaders are the response headers readable by clients, besides the
+	// CORS-safelisted ones.
+	ExposedHeaders []string `yaml:"exposedHeaders" env:"CORS_EXPOSED_HEADERS"`
+	// AllowCredentials lets browsers send cookies and TLS client certificates.
+	AllowCredentials bool `yaml:"allowCredentials" env:"CORS_ALLOW_CREDENTIALS"`
+	// MaxAge is how long browsers may cache the result of preflight requests.
+	MaxAge time.Duration `yaml:"maxAge" env:"CORS_MAX_AGE"`
+}
+
+// Security configures the security headers of responses. Empty headers aren't sent.
+type Security struct {
+	// HSTSMaxAge is the max-age of the Strict-Transport-Security header, sent on
+	// requests made over HTTPS. 0 disables it.
+	HSTSMaxAge            time.Duration `yaml:"hstsMaxAge" env:"SECURITY_HSTS_MAX_AGE"`
+	HSTSIncludeSubdomains bool          `yaml:"hstsIncludeSubdomains" env:"SECURITY_HSTS_INCLUDE_SUBDOMAINS"`
+	// FrameOptions is the X-Frame-Options header, 'DENY' or 'SAMEORIGIN'.
+	FrameOptions string `yaml:"frameOptions" env:"SECURITY_FRAME_OPTIONS"`
+	// ContentSecurityPolicy is the Content-Security-Policy header of the API, and
+	// SwaggerContentSecurityPolicy that of the Swagger UI.
+	ContentSecurityPolicy        string `yaml:"contentSecurityPolicy" env:"SECURITY_CONTENT_SECURITY_POLICY"`
+	SwaggerContentSecurityPolicy string `yaml:"swaggerContentSecurityPolicy" env:"SECURITY_SWAGGER_CONTENT_SECURITY_POLICY"`
+}
+
+// Default returns the Config used for settings which aren't set anywhere else.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:              "8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   25 * time.Second,
+			TLS: TLS{
+				MinVersion:     "1.2",
+				ReloadInterval: time.Minute,
+				ClientAuth:     "none",
+			},
+		},
+		Log: Log{
+			Output:            "file",
+			Level:             "info",
+			File:              "file.log",
+			MaxSizeMB:         100,
+			MaxBackups:        5,
+			MaxAgeDays:        30,
+			Compress:          true,
+			SampleSuccess:     1,
+			RedactQueryParams: []string{"token", "access_token", "password", "secret", "api_key"},
+			RedactHeaders:     []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+		},
+		Services: Services{
+			NameMaxLength: 50,
+		},
+		Specs: Specs{
+			Storage:    "filesystem",
+			StorageDir: "data/specs",
+		},
+		Deployments: Deployments{
+			Environments: []string{"dev", "staging", "prod"},
+		},
+		HealthCheck: HealthCheck{
+			Timeout: 2 * time.Second,
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			File:        "traces.json",
+			SampleRatio: 1,
+		},
+		RateLimit: RateLimit{
+			Enabled:  true,
+			Store:    "memory",
+			Auth:     RateLimitPolicy{Requests: 10, Period: time.Minute, Burst: 10},
+			Services: RateLimitPolicy{Requests: 600, Period: time.Minute, Burst: 100},
+			Catalog:  RateLimitPolicy{Requests: 10, Period: time.Minute, Burst: 5},
+			Default:  RateLimitPolicy{Requests: 600, Period: time.Minute, Burst: 100},
+			ClientIP: RateLimitPolicy{Requests: 1200, Period: time.Minute, Burst: 200},
+		},
+		CORS: CORS{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowedHeaders: []string{"Authorization", "Content-Type", "If-Match", "If-None-Match", "X-Request-ID"},
+			ExposedHeaders: []string{
+				"ETag", "Location", "X-Request-ID", "Retry-After",
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+			},
+			MaxAge: 10 * time.Minute,
+		},
+		Security: Security{
+			HSTSMaxAge:            365 * 24 * time.Hour,
+			FrameOptions:          "DENY",
+			ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+			// The Swagger UI uses inline scripts and styles, and data: images.
+			SwaggerContentSecurityPolicy: "default-src 'self'; script-src 'self' 'unsafe-inline'; " +
+				"style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'",
+		},
+	}
+}
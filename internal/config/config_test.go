@@ -0,0 +1,112 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setRequiredEnv sets the env vars of the settings which have no default, so that
+// they aren't reported as missing.
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("POSTGRES_HOST", "localhost")
+	t.Setenv("POSTGRES_PORT", "5432")
+	t.Setenv("POSTGRES_DB_NAME", "catalog")
+	t.Setenv("POSTGRES_USER", "catalog")
+	t.Setenv("POSTGRES_PASSWORD", "secret")
+	t.Setenv("TOKEN_HOUR_LIFESPAN", "1")
+}
+
+func TestLoadConfig(t *testing.T) {
+	setRequiredEnv(t)
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(configFile, []byte(`
+server:
+  port: "7000"
+  writeTimeout: 30s
+log:
+  file: from-file.log
+services:
+  nameMaxLength: 30
+promotion:
+  minSoakTime:
+    prod: 1h
+`), 0o600)
+	assert.NoError(t, err)
+	keyFile := filepath.Join(dir, "jwt-key")
+	err = os.WriteFile(keyFile, []byte("key-from-file\n"), 0o600)
+	assert.NoError(t, err)
+
+	t.Setenv("CONFIG_FILE", configFile)
+	t.Setenv("SERVER_PORT", "7001")
+	t.Setenv("SERVICE_NAME_MAX_LENGTH", "40")
+	t.Setenv("JWT_SIGNING_KEY", "")
+	t.Setenv("JWT_SIGNING_KEY_FILE", keyFile)
+
+	cfg, err := Load([]string{"-server.port", "7002"})
+	assert.NoError(t, err)
+	// Flags take precedence over env vars, which take precedence over the file.
+	assert.Equal(t, "7002", cfg.Server.Port)
+	assert.Equal(t, 40, cfg.Services.NameMaxLength)
+	assert.Equal(t, "from-file.log", cfg.Log.File)
+	assert.Equal(t, 30*time.Second, cfg.Server.WriteTimeout)
+	assert.Equal(t, map[string]time.Duration{"prod": time.Hour}, cfg.Promotion.MinSoakTime)
+	// Settings which aren't set anywhere keep their default.
+	assert.Equal(t, 5*time.Second, cfg.Server.ReadHeaderTimeout)
+	assert.Equal(t, []string{"dev", "staging", "prod"}, cfg.Deployments.Environments)
+	assert.Equal(t, "key-from-file", cfg.Auth.JWTSigningKey)
+
+	var out bytes.Buffer
+	err = cfg.Print(&out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "jwtSigningKey: '[REDACTED]'")
+	assert.NotContains(t, out.String(), "key-from-file")
+	assert.Contains(t, out.String(), `port: "7002"`)
+	assert.Equal(t, "key-from-file", cfg.Auth.JWTSigningKey)
+}
+
+func TestLoadInvalidConfig(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("JWT_SIGNING_KEY", "test-key")
+	t.Setenv("TRACING_EXPORTER", "jaeger")
+	t.Setenv("JWT_SIGNING_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	cfg, err := Load([]string{
+		"-server.port", "0",
+		"-server.trustedProxies", "10.0.0.0/8,proxy.internal",
+		"-server.tls.clientAuth", "require",
+		"-log.level", "loud",
+		"-auth.tokenHourLifespan", "a day",
+		"-deployments.environments", "dev,Prod,dev",
+		"-promotion.requiredApprovals", "qa=1",
+	})
+	var configErr *Error
+	if !errors.As(err, &configErr) {
+		t.Fatalf("Expected an Error, got: %v", err)
+	}
+	assert.NotNil(t, cfg)
+	// All problems are reported at once.
+	assert.Equal(t, []string{
+		"invalid value for flag -auth.tokenHourLifespan: a day; must be an integer",
+		"auth.jwtSigningKey: must not be set along with auth.jwtSigningKeyFile",
+		"server.port (SERVER_PORT): must be a port number, got \"0\"",
+		"server.trustedProxies (SERVER_TRUSTED_PROXIES): must be IP addresses or CIDR ranges, got \"proxy.internal\"",
+		"server.tls.clientAuth (SERVER_TLS_CLIENT_AUTH): requires server.tls.certFile",
+		"server.tls.clientCAFile (SERVER_TLS_CLIENT_CA_FILE): is required",
+		"log.level (LOG_LEVEL): must be one of: trace, debug, info, warn, error, disabled; got \"loud\"",
+		"deployments.environments (ENVIRONMENTS): \"Prod\" must be a DNS label of at most 50 characters",
+		"deployments.environments (ENVIRONMENTS): \"dev\" is listed twice",
+		"promotion.requiredApprovals (PROMOTION_REQUIRED_APPROVALS): unknown stage qa",
+		"tracing.exporter (TRACING_EXPORTER): must be one of: none, stdout, file, otlp; got \"jaeger\"",
+	}, configErr.Problems)
+
+	_, err = Load([]string{"-unknown"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces the value of secrets in the output of Redacted.
+const redacted = "[REDACTED]"
+
+// Error lists all the problems found while loading a Config.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid configuration:\n  - %s", strings.Join(e.Problems, "\n  - "))
+}
+
+// setting is a single setting of a Config.
+type setting struct {
+	// key is the dot separated YAML path of the setting, which is also the name of its flag.
+	key    string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+// settings returns the settings of the Config, in the order of its fields. The env
+// tag of a nested struct, if any, prefixes the env vars of its fields.
+func settings(c *Config) []setting {
+	var result []setting
+	var walk func(v reflect.Value, keyPrefix, envPrefix string)
+	walk = func(v reflect.Value, keyPrefix, envPrefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			key := keyPrefix + f.Tag.Get("yaml")
+			env := envPrefix + f.Tag.Get("env")
+			if f.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), key+".", env)
+				continue
+			}
+			result = append(result, setting{
+				key:    key,
+				env:    env,
+				secret: f.Tag.Get("secret") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "", "")
+	return result
+}
+
+// Load loads the Config from, in increasing order of precedence: the defaults, the
+// YAML file passed with the -config flag or the CONFIG_FILE env var, env vars and
+// flags. Empty env vars are ignored. Secrets can be read from the files set in their
+// *File settings. args are the command line arguments, without the program name.
+//
+// If the Config is invalid, it's returned along with an *Error listing all problems.
+// An error is returned without a Config only if the flags can't be parsed.
+func Load(args []string) (*Config, error) {
+	c := Default()
+	all := settings(&c)
+
+	fs := flag.NewFlagSet("service-catalog", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path of the YAML configuration file (CONFIG_FILE)")
+	flagValues := make(map[string]*string, len(all))
+	for _, s := range all {
+		flagValues[s.key] = fs.String(s.key, "", fmt.Sprintf("overrides %s", s.env))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	var problems []string
+	if *configFile != "" {
+		if err := loadFile(&c, *configFile); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	for _, s := range all {
+		value := os.Getenv(s.env)
+		if value == "" {
+			continue
+		}
+		if err := setFromString(s.value, value); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid value for env var %s: %s; %v", s.env, value, err))
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			return
+		}
+		value := *flagValues[f.Name]
+		s := findSetting(all, f.Name)
+		if err := setFromString(s.value, value); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid value for flag -%s: %s; %v", f.Name, value, err))
+		}
+	})
+
+	problems = append(problems, loadSecretFiles(&c)...)
+	problems = append(problems, c.validate()...)
+	if len(problems) > 0 {
+		return &c, &Error{Problems: problems}
+	}
+	return &c, nil
+}
+
+func findSetting(all []setting, key string) setting {
+	for _, s := range all {
+		if s.key == key {
+			return s
+		}
+	}
+	panic(fmt.Sprintf("unknown setting %s", key))
+}
+
+// loadFile decodes the YAML file into the Config, rejecting unknown keys.
+func loadFile(c *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read config file: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadSecretFiles reads the secrets whose *File setting is set. Trailing newlines
+// are trimmed from the content of the files.
+func loadSecretFiles(c *Config) []string {
+	secrets := []struct {
+		key   string
+		value *string
+		file  string
+	}{
+		{"database.password", &c.Database.Password, c.Database.PasswordFile},
+		{"auth.jwtSigningKey", &c.Auth.JWTSigningKey, c.Auth.JWTSigningKeyFile},
+	}
+	var problems []string
+	for _, secret := range secrets {
+		if secret.file == "" {
+			continue
+		}
+		if *secret.value != "" {
+			problems = append(problems, fmt.Sprintf("%s: must not be set along with %sFile", secret.key, secret.key))
+			continue
+		}
+		content, err := os.ReadFile(secret.file)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%sFile: unable to read secret: %v", secret.key, err))
+			continue
+		}
+		*secret.value = strings.TrimRight(string(content), "\r\n")
+	}
+	return problems
+}
+
+// setFromString parses the value of an env var or flag into the setting. Lists are
+// comma separated, and maps comma separated key=value pairs.
+func setFromString(v reflect.Value, value string) error {
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("must be a duration like '30s'")
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice:
+		items := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = reflect.Append(items, reflect.ValueOf(item))
+			}
+		}
+		v.Set(items)
+	case v.Kind() == reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, pair := range strings.Split(value, ",") {
+			key, item, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				return errors.New("must be a comma separated list of key=value pairs")
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setFromString(elem, item); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(key), elem)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// Redacted returns a copy of the Config where secrets are replaced, so that it can
+// be printed.
+func (c Config) Redacted() Config {
+	for _, s := range settings(&c) {
+		if s.secret && s.value.String() != "" {
+			s.value.SetString(redacted)
+		}
+	}
+	return c
+}
+
+// Print writes the Config as YAML, with secrets redacted.
+func (c Config) Print(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// serviceNameColumnLength is the length of the services.name column.
+	serviceNameColumnLength = 255
+)
+
+var (
+	environmentPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,48}[a-z0-9])?$`)
+	// httpTokenPattern matches the tokens of RFC 9110, like methods and header names.
+	httpTokenPattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+)
+
+// validate returns all problems of the Config, naming the settings by their YAML
+// key and env var.
+func (c *Config) validate() []string {
+	var problems []string
+	problem := func(key, format string, args ...interface{}) {
+		s := findSetting(settings(c), key)
+		problems = append(problems, fmt.Sprintf("%s (%s): %s", key, s.env, fmt.Sprintf(format, args...)))
+	}
+	required := func(key, value string) {
+		if value == "" {
+			problem(key, "is required")
+		}
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		problem("server.port", "must be a port number, got %q", c.Server.Port)
+	}
+	timeouts := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"server.readTimeout", c.Server.ReadTimeout},
+		{"server.readHeaderTimeout", c.Server.ReadHeaderTimeout},
+		{"server.writeTimeout", c.Server.WriteTimeout},
+		{"server.idleTimeout", c.Server.IdleTimeout},
+		{"server.shutdownTimeout", c.Server.ShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			problem(timeout.key, "must not be negative")
+		}
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			problem("server.trustedProxies", "must be IP addresses or CIDR ranges, got %q", proxy)
+		}
+	}
+
+	tls := c.Server.TLS
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		problem("server.tls.keyFile", "must be set along with server.tls.certFile")
+	}
+	versions := []string{"1.2", "1.3"}
+	if !contains(versions, tls.MinVersion) {
+		problem("server.tls.minVersion", "must be one of: %s; got %q", strings.Join(versions, ", "), tls.MinVersion)
+	}
+	if tls.ReloadInterval < 0 {
+		problem("server.tls.reloadInterval", "must not be negative")
+	}
+	clientAuths := []string{"none", "optional", "require"}
+	if !contains(clientAuths, tls.ClientAuth) {
+		problem("server.tls.clientAuth", "must be one of: %s; got %q", strings.Join(clientAuths, ", "), tls.ClientAuth)
+	} else if tls.ClientAuth != "none" {
+		if tls.CertFile == "" {
+			problem("server.tls.clientAuth", "requires server.tls.certFile")
+		}
+		required("server.tls.clientCAFile", tls.ClientCAFile)
+	}
+	for _, identity := range sortedKeys(tls.ClientIdentities) {
+		kind, value, _ := strings.Cut(identity, ":")
+		if !contains([]string{"cn", "uri", "dns", "email"}, kind) || value == "" {
+			problem("server.tls.clientIdentities", "must be prefixed with cn:, uri:, dns: or email:, got %q", identity)
+		}
+	}
+
+	required("database.host", c.Database.Host)
+	required("database.port", c.Database.Port)
+	required("database.name", c.Database.Name)
+	required("database.user", c.Database.User)
+	required("database.password", c.Database.Password)
+
+	required("auth.jwtSigningKey", c.Auth.JWTSigningKey)
+	if c.Auth.TokenHourLifespan < 1 {
+		problem("auth.tokenHourLifespan", "must be a positive number of hours")
+	}
+
+	outputs := []string{"file", "stdout", "console"}
+	if !contains(outputs, c.Log.Output) {
+		problem("log.output", "must be one of: %s; got %q", strings.Join(outputs, ", "), c.Log.Output)
+	}
+	levels := []string{"trace", "debug", "info", "warn", "error", "disabled"}
+	if !contains(levels, c.Log.Level) {
+		problem("log.level", "must be one of: %s; got %q", strings.Join(levels, ", "), c.Log.Level)
+	}
+	if c.Log.Output == "file" {
+		required("log.file", c.Log.File)
+		if c.Log.MaxSizeMB < 1 {
+			problem("log.maxSizeMB", "must be a positive number of megabytes")
+		}
+		if c.Log.MaxBackups < 0 {
+			problem("log.maxBackups", "must not be negative")
+		}
+		if c.Log.MaxAgeDays < 0 {
+			problem("log.maxAgeDays", "must not be negative")
+		}
+	}
+	if c.Log.SampleSuccess < 1 {
+		problem("log.sampleSuccess", "must be a positive integer")
+	}
+
+	if c.Services.NameMaxLength < 1 || c.Services.NameMaxLength > serviceNameColumnLength {
+		problem("services.nameMaxLength", "must be an integer between 1 and %d", serviceNameColumnLength)
+	}
+	if _, err := regexp.Compile(c.Services.NamePattern); err != nil {
+		problem("services.namePattern", "%v", err)
+	}
+
+	switch c.Specs.Storage {
+	case "filesystem":
+		required("specs.storageDir", c.Specs.StorageDir)
+	case "database":
+	default:
+		problem("specs.storage", "must be one of: filesystem, database; got %q", c.Specs.Storage)
+	}
+
+	stages := make(map[string]bool)
+	for _, env := range c.Deployments.Environments {
+		if !environmentPattern.MatchString(env) {
+			problem("deployments.environments", "%q must be a DNS label of at most 50 characters", env)
+		} else if stages[env] {
+			problem("deployments.environments", "%q is listed twice", env)
+		}
+		stages[env] = true
+	}
+	if len(c.Deployments.Environments) == 0 {
+		problem("deployments.environments", "must not be empty")
+	}
+	for _, stage := range sortedKeys(c.Promotion.MinSoakTime) {
+		soak := c.Promotion.MinSoakTime[stage]
+		if !stages[stage] {
+			problem("promotion.minSoakTime", "unknown stage %s", stage)
+		} else if soak < 0 {
+			problem("promotion.minSoakTime", "soak time for stage %s must not be negative", stage)
+		}
+	}
+	for _, stage := range sortedKeys(c.Promotion.RequiredApprovals) {
+		approvals := c.Promotion.RequiredApprovals[stage]
+		if !stages[stage] {
+			problem("promotion.requiredApprovals", "unknown stage %s", stage)
+		} else if approvals < 0 {
+			problem("promotion.requiredApprovals", "number of approvals for stage %s must not be negative", stage)
+		}
+	}
+
+	if c.HealthCheck.Timeout <= 0 {
+		problem("healthCheck.timeout", "must be a positive duration")
+	}
+
+	exporters := []string{"none", "stdout", "file", "otlp"}
+	if !contains(exporters, c.Tracing.Exporter) {
+		problem("tracing.exporter", "must be one of: %s; got %q", strings.Join(exporters, ", "), c.Tracing.Exporter)
+	}
+	if c.Tracing.Exporter == "file" {
+		required("tracing.file", c.Tracing.File)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problem("tracing.sampleRatio", "must be a number between 0 and 1")
+	}
+
+	stores := []string{"memory", "postgres"}
+	if !contains(stores, c.RateLimit.Store) {
+		problem("rateLimit.store", "must be one of: %s; got %q", strings.Join(stores, ", "), c.RateLimit.Store)
+	}
+	policies := []struct {
+		key    string
+		policy RateLimitPolicy
+	}{
+		{"rateLimit.auth", c.RateLimit.Auth},
+		{"rateLimit.services", c.RateLimit.Services},
+		{"rateLimit.catalog", c.RateLimit.Catalog},
+		{"rateLimit.default", c.RateLimit.Default},
+		{"rateLimit.clientIP", c.RateLimit.ClientIP},
+	}
+	for _, p := range policies {
+		if p.policy.Requests < 1 {
+			problem(p.key+".requests", "must be a positive integer")
+		}
+		if p.policy.Period <= 0 {
+			problem(p.key+".period", "must be a positive duration")
+		}
+		if p.policy.Burst < 1 {
+			problem(p.key+".burst", "must be a positive integer")
+		}
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				problem("cors.allowedOrigins", "must not contain '*' when credentials are allowed")
+			}
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+			u.Host == "" || u.Path != "" || u.RawQuery != "" || u.User != nil {
+			problem("cors.allowedOrigins", "%q must be an origin like 'https://catalog.example.com'", origin)
+		}
+	}
+	for _, method := range c.CORS.AllowedMethods {
+		if !httpTokenPattern.MatchString(method) || method != strings.ToUpper(method) {
+			problem("cors.allowedMethods", "%q must be an uppercase HTTP method", method)
+		}
+	}
+	headers := []struct {
+		key    string
+		values []string
+	}{
+		{"cors.allowedHeaders", c.CORS.AllowedHeaders},
+		{"cors.exposedHeaders", c.CORS.ExposedHeaders},
+	}
+	for _, h := range headers {
+		for _, header := range h.values {
+			if !httpTokenPattern.MatchString(header) {
+				problem(h.key, "%q must be a header name", header)
+			}
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		problem("cors.maxAge", "must not be negative")
+	}
+
+	if c.Security.HSTSMaxAge < 0 {
+		problem("securityHeaders.hstsMaxAge", "must not be negative")
+	}
+	if !contains([]string{"", "DENY", "SAMEORIGIN"}, c.Security.FrameOptions) {
+		problem("securityHeaders.frameOptions", "must be one of: DENY, SAMEORIGIN or empty; got %q", c.Security.FrameOptions)
+	}
+	return problems
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+// Package logging carries the ID of a request and a logger through its context, so
+// that log lines and audit records can be correlated with the request.
+package logging
+
+import (
+	"context"
+	"os"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+var logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+// SetLogger sets the logger returned by FromContext.
+func SetLogger(l zerolog.Logger) {
+	logger = l
+}
+
+// WithRequestID returns a copy of the context carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID of the request the context belongs to, or "" if it
+// doesn't belong to a request.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// FromContext returns a logger which adds the request ID and the current trace and
+// span IDs of the context to every line.
+func FromContext(ctx context.Context) *zerolog.Logger {
+	fields := logger.With()
+	if id := RequestID(ctx); id != "" {
+		fields = fields.Str("request_id", id)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = fields.Str("trace_id", sc.TraceID().String()).
+			Str("span_id", sc.SpanID().String())
+	}
+	l := fields.Logger()
+	return &l
+}
@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/natefinch/lumberjack"
+	"github.com/rs/zerolog"
+)
+
+// New returns a logger writing to the output configured by cfg, which must be
+// valid, along with the sink to close once nothing is logged anymore.
+func New(cfg config.Log) (zerolog.Logger, io.Closer) {
+	var w io.Writer
+	var closer io.Closer = nopCloser{}
+	switch cfg.Output {
+	case "stdout":
+		w = os.Stdout
+	case "console":
+		w = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	default:
+		file := &lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		}
+		w, closer = file, file
+	}
+
+	level, err := zerolog.ParseLevel(cfg.Level)
+	if err != nil || cfg.Level == "" {
+		level = zerolog.InfoLevel
+	}
+	return zerolog.New(w).Level(level).With().Timestamp().Logger(), closer
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startTimeKey = "metrics:start_time"
+
+// gormPlugin is a GORM plugin recording the duration of every query, and whether it failed.
+type gormPlugin struct{}
+
+// Name implements gorm.Plugin.
+func (gormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize implements gorm.Plugin, registering callbacks which run first and
+// last for every kind of operation.
+func (gormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	registrations := []error{
+		callbacks.Create().Before("*").Register("metrics:before_create", before),
+		callbacks.Create().After("*").Register("metrics:after_create", after("create")),
+		callbacks.Query().Before("*").Register("metrics:before_query", before),
+		callbacks.Query().After("*").Register("metrics:after_query", after("query")),
+		callbacks.Update().Before("*").Register("metrics:before_update", before),
+		callbacks.Update().After("*").Register("metrics:after_update", after("update")),
+		callbacks.Delete().Before("*").Register("metrics:before_delete", before),
+		callbacks.Delete().After("*").Register("metrics:after_delete", after("delete")),
+		callbacks.Row().Before("*").Register("metrics:before_row", before),
+		callbacks.Row().After("*").Register("metrics:after_row", after("row")),
+		callbacks.Raw().Before("*").Register("metrics:before_raw", before),
+		callbacks.Raw().After("*").Register("metrics:after_raw", after("raw")),
+	}
+	return errors.Join(registrations...)
+}
+
+func before(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+func after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		dbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			dbQueryErrorsTotal.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
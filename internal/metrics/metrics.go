@@ -0,0 +1,90 @@
+// Package metrics exposes Prometheus metrics about HTTP requests, database queries
+// and the contents of the catalog.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const namespace = "service_catalog"
+
+// Registry holds all metrics exposed by Handler, including the Go runtime and
+// process metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests handled, by route template, method and status code.",
+	}, []string{"route", "method", "status"})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests, by route template, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database queries, by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+	dbQueryErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "Number of failed database queries, by operation and table.",
+	}, []string{"operation", "table"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		dbQueryDuration,
+		dbQueryErrorsTotal,
+	)
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus format.
+// Metrics which can't be collected, e.g. while the database is unavailable, are
+// left out instead of failing the whole scrape.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
+// ObserveHTTPRequest records a handled HTTP request. route must be the route
+// template, like '/services/:id', to keep the number of label values bounded.
+func ObserveHTTPRequest(route, method, status string, seconds float64) {
+	httpRequestsTotal.WithLabelValues(route, method, status).Inc()
+	httpRequestDuration.WithLabelValues(route, method, status).Observe(seconds)
+}
+
+// RegisterDB instruments the database handler: query durations and errors are
+// recorded by a GORM plugin, and the connection pool stats and the number of
+// services, versions and users are collected on every scrape.
+func RegisterDB(db *gorm.DB) error {
+	if err := db.Use(gormPlugin{}); err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := Registry.Register(collectors.NewDBStatsCollector(sqlDB, "service_catalog")); err != nil {
+		return err
+	}
+	return Registry.Register(newCatalogCollector(db))
+}
@@ -1,39 +1,88 @@
 package middleware
 
 import (
+	"crypto/x509"
 	"errors"
 	"fmt"
-	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/aryan9600/service-catalog/internal/auth"
+	"github.com/aryan9600/service-catalog/internal/logging"
 	"github.com/aryan9600/service-catalog/internal/models"
 	"github.com/gin-gonic/gin"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // JwtAuthMiddleware returns a middleware that checks if the request originates
-// from an authenticated user. If it does, it sets the user's ID in the request's
-// context under the 'userID' key.
+// from an authenticated user, either by a bearer token or, without one, by a
+// verified client certificate whose identity is mapped to the user. If it does, it
+// sets the user's ID in the request's context under the 'userID' key, and whether
+// the user is an admin under the 'isAdmin' key. The user ID is also recorded on the
+// request's span.
 func JwtAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token := extractToken(c)
-		userID, err := auth.ExtractUserIDFromToken(token)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthenticated"})
-			c.Abort()
-			return
+		var user *models.User
+		var err error
+		if token, cert := extractToken(c), clientCertificate(c); token == "" && cert != nil {
+			user, err = userFromCertificate(c, cert)
+		} else {
+			user, err = userFromToken(c, token)
 		}
-		_, err = models.GetUserByID(userID)
 		if err != nil {
-			if errors.Is(err, models.ErrRecordNotFound) {
-				c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthenticated"})
+			if errors.Is(err, models.ErrRecordNotFound) || errors.Is(err, models.ErrUnauthenticated) {
+				c.Error(models.ErrUnauthenticated)
 			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"message": fmt.Sprintf("unable to fetch user")})
+				c.Error(fmt.Errorf("unable to fetch user: %w", err))
 			}
 			c.Abort()
 			return
 		}
-		c.Set("userID", userID)
+		c.Set("userID", user.ID)
+		c.Set("isAdmin", user.IsAdmin)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(semconv.EnduserID(strconv.FormatUint(uint64(user.ID), 10)))
+		c.Next()
+	}
+}
+
+func userFromToken(c *gin.Context, token string) (*models.User, error) {
+	userID, err := auth.ExtractUserIDFromToken(token)
+	if err != nil {
+		return nil, models.ErrUnauthenticated
+	}
+	return models.GetUserByID(c.Request.Context(), userID)
+}
+
+func userFromCertificate(c *gin.Context, cert *x509.Certificate) (*models.User, error) {
+	username, ok := auth.UsernameFromCertificate(cert)
+	if !ok {
+		logging.FromContext(c.Request.Context()).Info().
+			Strs("identities", auth.CertificateIdentities(cert)).
+			Msg("client certificate is not mapped to a user")
+		return nil, models.ErrUnauthenticated
+	}
+	return models.GetUserByUsername(c.Request.Context(), username)
+}
+
+// clientCertificate returns the client certificate of the request if it was
+// verified during the TLS handshake, and nil otherwise.
+func clientCertificate(c *gin.Context) *x509.Certificate {
+	if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+		return nil
+	}
+	return c.Request.TLS.VerifiedChains[0][0]
+}
+
+// AdminOnly returns a middleware that rejects requests of users who aren't admins.
+// It must be used after JwtAuthMiddleware.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("isAdmin") {
+			c.Error(models.ErrForbidden)
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS returns a middleware that lets browser clients of the origins allowed by cfg
+// call the API. Preflight requests are answered directly, with a 403 if the origin,
+// method or headers aren't allowed. Other requests of origins which aren't allowed
+// are served without CORS headers, so that browsers don't expose the response.
+func CORS(cfg config.CORS) gin.HandlerFunc {
+	anyOrigin := false
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+		origins[strings.ToLower(origin)] = true
+	}
+	methods := make(map[string]bool, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		methods[method] = true
+	}
+	headers := lowerSet(cfg.AllowedHeaders)
+	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.FormatInt(int64(cfg.MaxAge.Seconds()), 10)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if len(origins) == 0 || origin == "" {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if !anyOrigin && !origins[strings.ToLower(origin)] {
+			if preflight {
+				c.Error(NewProblem(http.StatusForbidden, "cors_rejected", "origin is not allowed"))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if anyOrigin && !cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposedHeaders != "" {
+				c.Header("Access-Control-Expose-Headers", exposedHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		if method := c.GetHeader("Access-Control-Request-Method"); !methods[method] {
+			c.Error(NewProblem(http.StatusForbidden, "cors_rejected", "method "+method+" is not allowed"))
+			c.Abort()
+			return
+		}
+		for _, header := range strings.Split(c.GetHeader("Access-Control-Request-Headers"), ",") {
+			header = strings.TrimSpace(header)
+			if header != "" && !headers[strings.ToLower(header)] {
+				c.Error(NewProblem(http.StatusForbidden, "cors_rejected", "header "+header+" is not allowed"))
+				c.Abort()
+				return
+			}
+		}
+		c.Header("Access-Control-Allow-Methods", allowedMethods)
+		if allowedHeaders != "" {
+			c.Header("Access-Control-Allow-Headers", allowedHeaders)
+		}
+		c.Header("Access-Control-Max-Age", maxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
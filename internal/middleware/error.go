@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aryan9600/service-catalog/internal/logging"
+	"github.com/aryan9600/service-catalog/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem represents an RFC 7807 problem details object. Code is a stable, machine
+// readable identifier of the problem, and RequestID the ID of the request to quote
+// when reporting it. Problem also implements error, so that handlers can report
+// problems which don't originate from the models package.
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	Errors    []models.FieldError `json:"errors,omitempty"`
+	RequestID string              `json:"requestID,omitempty"`
+}
+
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+// NewProblem returns a Problem with the provided status, code and detail.
+func NewProblem(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// ProblemFromError translates an error into a Problem. Errors of the models package
+// are reported as is, while any other error results in a generic internal error so
+// that no implementation details, such as SQL, are leaked to clients.
+func ProblemFromError(err error) *Problem {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		p := *problem
+		p.Detail = err.Error()
+		return &p
+	}
+
+	var modelErr *models.Error
+	if !errors.As(err, &modelErr) {
+		return NewProblem(http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+	p := NewProblem(statusForErrorKind(modelErr.Kind), modelErr.Code, err.Error())
+	p.Errors = modelErr.Fields
+	return p
+}
+
+func statusForErrorKind(kind models.ErrorKind) int {
+	switch kind {
+	case models.ErrorKindNotFound:
+		return http.StatusNotFound
+	case models.ErrorKindConflict:
+		return http.StatusConflict
+	case models.ErrorKindValidation:
+		return http.StatusBadRequest
+	case models.ErrorKindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case models.ErrorKindForbidden:
+		return http.StatusForbidden
+	case models.ErrorKindUnauthenticated:
+		return http.StatusUnauthorized
+	case models.ErrorKindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ErrorHandler returns a middleware that translates the last error attached to the
+// request's context into an application/problem+json response, unless a response
+// has already been written. Handlers should attach the error using c.Error()
+// and return.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		problem := ProblemFromError(c.Errors.Last().Err)
+		problem.Instance = c.Request.URL.Path
+		problem.RequestID = logging.RequestID(c.Request.Context())
+		c.Header("Content-Type", problemContentType)
+		c.Render(problem.Status, render.JSON{Data: problem})
+	}
+}
@@ -1,14 +1,33 @@
 package middleware
 
 import (
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/logging"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// StructuredLogger logs a HTTP request with some metadata in JSON.
-func StructuredLogger(logger *zerolog.Logger) gin.HandlerFunc {
+// redacted replaces the values of sensitive query parameters and headers in logs.
+const redacted = "REDACTED"
+
+// StructuredLogger logs a HTTP request with some metadata in JSON. Only one in every
+// cfg.SampleSuccess successful requests is logged, and the values of the query
+// parameters and headers listed in cfg are redacted. Request headers are only
+// logged if the logger's level is debug or lower.
+func StructuredLogger(logger *zerolog.Logger, cfg config.Log) gin.HandlerFunc {
+	successLogger := *logger
+	if cfg.SampleSuccess > 1 {
+		successLogger = logger.Sample(&zerolog.BasicSampler{N: uint32(cfg.SampleSuccess)})
+	}
+	redactedParams := lowerSet(cfg.RedactQueryParams)
+	redactedHeaders := lowerSet(cfg.RedactHeaders)
+
 	return func(c *gin.Context) {
 
 		start := time.Now() // Start the timer
@@ -31,16 +50,23 @@ func StructuredLogger(logger *zerolog.Logger) gin.HandlerFunc {
 		param.ErrorMessage = c.Errors.ByType(gin.ErrorTypePrivate).String()
 		param.BodySize = c.Writer.Size()
 		if raw != "" {
-			path = path + "?" + raw
+			path = path + "?" + redactQuery(raw, redactedParams)
 		}
 		param.Path = path
 
 		// Log using the params
 		var logEvent *zerolog.Event
-		if c.Writer.Status() >= 500 {
+		switch {
+		case c.Writer.Status() >= 500:
 			logEvent = logger.Error()
-		} else {
+		case c.Writer.Status() >= 400:
 			logEvent = logger.Info()
+		default:
+			logEvent = successLogger.Info()
+		}
+		// The event is nil if it's discarded because of its level or sampling.
+		if logEvent == nil {
+			return
 		}
 
 		logEvent.Str("client_id", param.ClientIP).
@@ -50,12 +76,59 @@ func StructuredLogger(logger *zerolog.Logger) gin.HandlerFunc {
 			Str("path", param.Path).
 			Str("latency", param.Latency.String())
 
+		if id := logging.RequestID(c.Request.Context()); id != "" {
+			logEvent.Str("request_id", id)
+		}
+
+		// Log the trace the request is part of, to correlate logs with traces.
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			logEvent.Str("trace_id", sc.TraceID().String()).
+				Str("span_id", sc.SpanID().String())
+		}
+
 		// If the user ID is set, then log that as well.
 		userID, ok := c.Get("userID")
 		if ok {
 			logEvent.Uint("user_id", userID.(uint))
 		}
 
+		if logger.GetLevel() <= zerolog.DebugLevel {
+			logEvent.Dict("headers", headersDict(c.Request.Header, redactedHeaders))
+		}
+
 		logEvent.Msg(param.ErrorMessage)
 	}
 }
+
+// redactQuery replaces the values of the redacted parameters of the raw query,
+// keeping the order of the parameters.
+func redactQuery(raw string, redactedParams map[string]bool) string {
+	pairs := strings.Split(raw, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && redactedParams[strings.ToLower(name)] {
+			pairs[i] = key + "=" + redacted
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func headersDict(header http.Header, redactedHeaders map[string]bool) *zerolog.Event {
+	dict := zerolog.Dict()
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if redactedHeaders[strings.ToLower(name)] {
+			value = redacted
+		}
+		dict.Str(name, value)
+	}
+	return dict
+}
+
+func lowerSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[strings.ToLower(v)] = true
+	}
+	return set
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the count and latency of HTTP requests, labeled by the route
+// template instead of the raw path. Requests matching no route share one label.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(route, c.Request.Method, strconv.Itoa(c.Writer.Status()), time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/logging"
+	"github.com/aryan9600/service-catalog/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit returns a middleware that limits the rate of requests according to the
+// policy, per user if JwtAuthMiddleware was used before it and per client IP
+// otherwise. Every response gets the RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers, and rejected requests a 429 with
+// a Retry-After header. If the limiter fails, requests are allowed.
+func RateLimit(limiter ratelimit.Limiter, policy ratelimit.Policy) gin.HandlerFunc {
+	policyHeader := fmt.Sprintf("%d;w=%d;burst=%d", policy.Requests, ceilSeconds(policy.Period), policy.Burst)
+	return func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if userID, ok := c.Get("userID"); ok {
+			key = fmt.Sprintf("user:%d", userID.(uint))
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), key, policy)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Warn().Err(err).
+				Str("policy", policy.Name).
+				Msg("unable to rate limit request")
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.Reset), 10))
+		c.Header("RateLimit-Policy", policyHeader)
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+			c.Error(NewProblem(http.StatusTooManyRequests, "rate_limited",
+				fmt.Sprintf("too many requests, retry in %d seconds", retryAfter)))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
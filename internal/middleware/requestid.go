@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/aryan9600/service-catalog/internal/logging"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header carrying the ID of a request.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern restricts the request IDs accepted from clients to printable
+// ASCII, so that they can't forge log lines.
+var requestIDPattern = regexp.MustCompile(`^[\x21-\x7e]{1,128}$`)
+
+// RequestID returns a middleware that assigns an ID to every request: the one sent
+// by the client in the X-Request-ID header if it's valid, or a random one. The ID is
+// stored in the request's context, see logging.RequestID, set under the 'requestID'
+// key, recorded on the request's span and returned in the X-Request-ID header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Set("requestID", id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("http.request_id", id))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read never returns an error on supported platforms.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerPathPrefix is the prefix of the paths of the Swagger UI.
+const swaggerPathPrefix = "/swagger/"
+
+// SecurityHeaders returns a middleware that sets the security headers configured by
+// cfg on every response: X-Content-Type-Options, Referrer-Policy, X-Frame-Options,
+// Content-Security-Policy, with a policy of its own for the Swagger UI, and
+// Strict-Transport-Security on requests made over HTTPS.
+func SecurityHeaders(cfg config.Security) gin.HandlerFunc {
+	var hsts string
+	if cfg.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int64(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("Referrer-Policy", "no-referrer")
+		if cfg.FrameOptions != "" {
+			h.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+		csp := cfg.ContentSecurityPolicy
+		if strings.HasPrefix(c.Request.URL.Path, swaggerPathPrefix) {
+			csp = cfg.SwaggerContentSecurityPolicy
+		}
+		if csp != "" {
+			h.Set("Content-Security-Policy", csp)
+		}
+		// HSTS is ignored by browsers over plain HTTP. The server may be behind a
+		// proxy terminating TLS, so the header is sent if the proxy says so.
+		if hsts != "" && (c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https") {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"context"
+
+	"github.com/aryan9600/service-catalog/internal/logging"
+)
+
+// requestID returns the ID of the request the context belongs to, recorded along
+// with audit records such as renames, deployments and promotions, or nil if the
+// context doesn't belong to a request.
+func requestID(ctx context.Context) *string {
+	id := logging.RequestID(ctx)
+	if id == "" {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrBatchAborted is set as the error of the operations of an atomic batch which
+// were rolled back or never executed because another operation failed.
+var ErrBatchAborted = errors.New("batch aborted")
+
+// BatchOperation represents a single operation executed as part of a batch.
+// Exactly one of CreateService, UpdateService and CreateVersion must be set.
+// ServiceID is required by UpdateService and CreateVersion.
+type BatchOperation struct {
+	ServiceID     uint
+	CreateService *CreateServiceInput
+	UpdateService *UpdateServiceInput
+	CreateVersion *CreateVersionInput
+}
+
+// BatchResult represents the outcome of a single BatchOperation.
+type BatchResult struct {
+	Service *Service
+	Version *Version
+	Err     error
+}
+
+// ExecuteBatch executes the provided operations on behalf of the user, in order.
+// If atomic is true, all operations are executed in a single transaction which is
+// rolled back at the first failure; the failed operation's result contains the
+// cause while all others contain ErrBatchAborted. Otherwise, each operation is
+// executed independently and failures don't affect the other operations.
+// The returned error is only set if the batch couldn't be executed at all.
+func ExecuteBatch(ctx context.Context, ops []BatchOperation, userID uint, atomic bool) ([]BatchResult, error) {
+	results := make([]BatchResult, len(ops))
+	if !atomic {
+		for i, op := range ops {
+			results[i] = executeBatchOperation(DB.WithContext(ctx), op, userID)
+		}
+		return results, nil
+	}
+
+	failed := -1
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, op := range ops {
+			results[i] = executeBatchOperation(tx, op, userID)
+			if results[i].Err != nil {
+				failed = i
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for _, result := range results {
+			discardSpecBlob(result.Version)
+		}
+	}
+	if err != nil && failed == -1 {
+		return nil, err
+	}
+	if failed != -1 {
+		for i := range results {
+			if i != failed {
+				results[i] = BatchResult{Err: ErrBatchAborted}
+			}
+		}
+	}
+	return results, nil
+}
+
+func executeBatchOperation(db *gorm.DB, op BatchOperation, userID uint) BatchResult {
+	var result BatchResult
+	switch {
+	case op.CreateService != nil:
+		input := *op.CreateService
+		input.UserID = userID
+		result.Service, result.Err = createService(db, input)
+	case op.UpdateService != nil:
+		result.Service, result.Err = updateService(db, *op.UpdateService, op.ServiceID, userID)
+	case op.CreateVersion != nil:
+		input := *op.CreateVersion
+		input.ServiceID = int(op.ServiceID)
+		input.UserID = userID
+		// Nested transactions use savepoints, so this works for atomic batches as well.
+		result.Err = db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			result.Version, err = createVersion(tx, input)
+			return err
+		})
+		if result.Err != nil {
+			discardSpecBlob(result.Version)
+			result.Version = nil
+		}
+	default:
+		result.Err = errors.New("no operation specified")
+	}
+	return result
+}
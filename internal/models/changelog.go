@@ -0,0 +1,163 @@
+package models
+
+import (
+	"bufio"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Types of changelog entries, in the order they are rendered.
+const (
+	ChangeTypeAdded    = "added"
+	ChangeTypeChanged  = "changed"
+	ChangeTypeRemoved  = "removed"
+	ChangeTypeFixed    = "fixed"
+	ChangeTypeSecurity = "security"
+)
+
+var changeTypes = []string{ChangeTypeAdded, ChangeTypeChanged, ChangeTypeRemoved, ChangeTypeFixed, ChangeTypeSecurity}
+
+// ChangelogEntry represents a single notable change in a Version.
+type ChangelogEntry struct {
+	Type        string   `json:"type" binding:"required,oneof=added changed removed fixed security"`
+	Description string   `json:"description" binding:"required,max=1000"`
+	Links       []string `json:"links,omitempty" binding:"max=10,dive,url,max=2048"`
+}
+
+// Changelog is a list of ChangelogEntry objects stored as a JSON array.
+type Changelog []ChangelogEntry
+
+// Value implements driver.Valuer, storing the entries as a JSON array.
+func (c Changelog) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]ChangelogEntry(c))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (c *Changelog) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. For backwards compatibility, a
+// changelog can also be a string, which becomes a single 'changed' entry.
+func (c *Changelog) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = nil
+		if strings.TrimSpace(text) != "" {
+			*c = Changelog{{Type: ChangeTypeChanged, Description: text}}
+		}
+		return nil
+	}
+	var entries []ChangelogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	*c = entries
+	return nil
+}
+
+// String returns the entries as plain text, one per line.
+func (c Changelog) String() string {
+	lines := make([]string, 0, len(c))
+	for _, entry := range c {
+		lines = append(lines, fmt.Sprintf("%s: %s", entry.Type, entry.Description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ChangelogRange represents a range of Versions of a Service, from From up to
+// and including To. Either of them can be empty to leave the range open.
+type ChangelogRange struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+// ListChangelogVersions returns the Versions of the Service within the range,
+// from newest to oldest. Versions are ordered as described by sortVersions.
+func ListChangelogVersions(ctx context.Context, svcID uint, userID uint, r ChangelogRange) (*Service, []Version, error) {
+	service, err := GetService(ctx, svcID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var versions []Version
+	if err := DB.WithContext(ctx).Table(VersionTableName).Where("service_id = ?", svcID).Find(&versions).Error; err != nil {
+		return nil, nil, err
+	}
+	sortVersions(versions)
+
+	from, to := 0, len(versions)-1
+	if r.From != "" {
+		if from = indexOfVersion(versions, r.From); from < 0 {
+			return nil, nil, fmt.Errorf("version %s: %w", r.From, ErrRecordNotFound)
+		}
+	}
+	if r.To != "" {
+		if to = indexOfVersion(versions, r.To); to < 0 {
+			return nil, nil, fmt.Errorf("version %s: %w", r.To, ErrRecordNotFound)
+		}
+	}
+	if from > to {
+		message := fmt.Sprintf("version %s comes after version %s", r.From, r.To)
+		return nil, nil, NewValidationError(message, FieldError{Field: "from", Message: "must not come after 'to'"})
+	}
+
+	selected := make([]Version, 0, to-from+1)
+	for i := to; i >= from; i-- {
+		selected = append(selected, versions[i])
+	}
+	return service, selected, nil
+}
+
+func indexOfVersion(versions []Version, version string) int {
+	for i, v := range versions {
+		if v.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// WriteChangelogMarkdown renders the changelogs of the Versions, which should be
+// ordered from newest to oldest, following the Keep a Changelog format
+// (https://keepachangelog.com).
+func WriteChangelogMarkdown(w io.Writer, service *Service, versions []Version) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Changelog\n\nAll notable changes to %s are documented here.\n\n", service.Name)
+	fmt.Fprint(bw, "The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).\n")
+
+	for _, v := range versions {
+		fmt.Fprintf(bw, "\n## [%s] - %s\n", v.Version, v.CreatedAt.UTC().Format("2006-01-02"))
+		for _, changeType := range changeTypes {
+			var entries []ChangelogEntry
+			for _, entry := range v.Changelog {
+				if entry.Type == changeType {
+					entries = append(entries, entry)
+				}
+			}
+			if len(entries) == 0 {
+				continue
+			}
+			fmt.Fprintf(bw, "\n### %s\n\n", strings.ToUpper(changeType[:1])+changeType[1:])
+			for _, entry := range entries {
+				// Entries are rendered as a single list item each.
+				line := "- " + strings.Join(strings.Fields(entry.Description), " ")
+				for _, link := range entry.Links {
+					line += fmt.Sprintf(" <%s>", link)
+				}
+				fmt.Fprintln(bw, line)
+			}
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,223 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/spec"
+	"gorm.io/gorm"
+)
+
+const CompatibilityReportTableName = "compatibility_reports"
+
+// CompatibilityReport represents the changes between the OpenAPI spec of a Version
+// and that of the previous Version of the Service which has one.
+type CompatibilityReport struct {
+	ID            uint        `json:"-"`
+	VersionID     uint        `json:"versionID"`
+	BaseVersionID uint        `json:"baseVersionID"`
+	BaseVersion   string      `json:"baseVersion" gorm:"->"`
+	Breaking      bool        `json:"breaking"`
+	Changes       SpecChanges `json:"changes"`
+	CreatedAt     time.Time   `json:"createdAt"`
+}
+
+// SpecChanges is a list of changes between two specs stored as a JSON array.
+type SpecChanges []spec.Change
+
+// Value implements driver.Valuer, storing the changes as a JSON array.
+func (c SpecChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (c *SpecChanges) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+// rejectBreakingChanges makes attaching a spec with breaking changes fail, unless
+// the version is a major bump of the base version.
+var rejectBreakingChanges bool
+
+// SetSpecCompatibilityConfig sets whether specs with breaking changes require a
+// major version bump.
+func SetSpecCompatibilityConfig(cfg config.Specs) {
+	rejectBreakingChanges = cfg.RejectBreakingChanges
+}
+
+// GetCompatibilityReport returns the CompatibilityReport of the provided Version of the Service.
+func GetCompatibilityReport(ctx context.Context, svcID uint, userID uint, version string) (*CompatibilityReport, error) {
+	v, err := getVersion(DB.WithContext(ctx), svcID, userID, version)
+	if err != nil {
+		return nil, err
+	}
+	report, err := getCompatibilityReport(DB.WithContext(ctx), v.ID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrRecordNotFound
+	}
+	return report, nil
+}
+
+func getCompatibilityReport(tx *gorm.DB, versionID uint) (*CompatibilityReport, error) {
+	var report CompatibilityReport
+	err := tx.Table(CompatibilityReportTableName).
+		Select(fmt.Sprintf("%s.*, %s.version AS base_version", CompatibilityReportTableName, VersionTableName)).
+		Joins(fmt.Sprintf("JOIN %s ON %s.id = %s.base_version_id", VersionTableName, VersionTableName, CompatibilityReportTableName)).
+		Where(fmt.Sprintf("%s.version_id = ?", CompatibilityReportTableName), versionID).
+		Find(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	if report.ID == 0 {
+		return nil, nil
+	}
+	return &report, nil
+}
+
+// checkCompatibility compares the OpenAPI spec with the one of the previous Version of
+// the Service which has an OpenAPI spec, see compatibilityBase. It returns nil if there
+// is no such Version or the spec isn't an OpenAPI spec. If breaking changes are rejected
+// and the Version isn't a major bump of the previous one, an error is returned.
+func checkCompatibility(tx *gorm.DB, v *Version, input PutVersionSpecInput) (*CompatibilityReport, error) {
+	if input.Format != spec.FormatOpenAPI {
+		return nil, nil
+	}
+	base, err := compatibilityBase(tx, v)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		return nil, nil
+	}
+	baseSpec, err := getVersionSpecByVersionID(tx, base.ID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := specStore.Get(tx.Statement.Context, baseSpec.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read spec %s: %w", baseSpec.StorageKey, err)
+	}
+	baseContent, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read spec %s: %w", baseSpec.StorageKey, err)
+	}
+
+	baseDoc, err := spec.LoadOpenAPI(baseContent)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load spec of version %s: %w", base.Version, err)
+	}
+	doc, err := spec.LoadOpenAPI(input.Content)
+	if err != nil {
+		return nil, err
+	}
+	changes := spec.Diff(baseDoc, doc)
+	report := &CompatibilityReport{
+		VersionID:     v.ID,
+		BaseVersionID: base.ID,
+		BaseVersion:   base.Version,
+		Breaking:      spec.Breaking(changes),
+		Changes:       changes,
+	}
+
+	if report.Breaking && rejectBreakingChanges {
+		// Without semantic versions there's no telling whether the version is a major
+		// bump, so the breaking changes are rejected as well.
+		major, ok := isMajorBump(base.Version, v.Version)
+		if !ok {
+			return nil, breakingChangeError(report, fmt.Sprintf("which requires both %s and %s to be semantic versions", base.Version, v.Version))
+		}
+		if !major {
+			return nil, breakingChangeError(report, "which requires a major version bump")
+		}
+	}
+	return report, nil
+}
+
+// compatibilityBase returns the Version whose OpenAPI spec the spec of v is compared
+// with, or nil if there is none. If v is a semantic version, that's the closest lower
+// semantic version with an OpenAPI spec, so that e.g. a patch release of an older major
+// version isn't compared with the latest one. Otherwise it's the last Version with an
+// OpenAPI spec created before v.
+func compatibilityBase(tx *gorm.DB, v *Version) (*Version, error) {
+	var candidates []Version
+	err := tx.Table(VersionTableName).Select(fmt.Sprintf("%s.*", VersionTableName)).
+		Joins(fmt.Sprintf("JOIN %s ON %s.version_id = %s.id", VersionSpecTableName, VersionSpecTableName, VersionTableName)).
+		Where(fmt.Sprintf("%s.service_id = ? AND %s.id <> ?", VersionTableName, VersionTableName), v.ServiceID, v.ID).
+		Where(fmt.Sprintf("%s.format = ?", VersionSpecTableName), spec.FormatOpenAPI).
+		Order(fmt.Sprintf("%s.id", VersionTableName)).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var lower []Version
+	if version, ok := parseSemver(v.Version); ok {
+		for _, c := range candidates {
+			if s, ok := parseSemver(c.Version); ok && s.compare(version) < 0 {
+				lower = append(lower, c)
+			}
+		}
+		sortVersions(lower)
+	} else {
+		for _, c := range candidates {
+			if c.ID < v.ID {
+				lower = append(lower, c)
+			}
+		}
+	}
+	if len(lower) == 0 {
+		return nil, nil
+	}
+	return &lower[len(lower)-1], nil
+}
+
+// saveCompatibilityReport replaces the CompatibilityReport of the Version with report,
+// which may be nil.
+func saveCompatibilityReport(tx *gorm.DB, versionID uint, report *CompatibilityReport) error {
+	if err := tx.Table(CompatibilityReportTableName).Where("version_id = ?", versionID).Delete(&CompatibilityReport{}).Error; err != nil {
+		return err
+	}
+	if report == nil {
+		return nil
+	}
+	return tx.Table(CompatibilityReportTableName).Create(report).Error
+}
+
+// breakingChangeError returns the error of a spec whose breaking changes are rejected,
+// where reason explains what they would require.
+func breakingChangeError(report *CompatibilityReport, reason string) error {
+	var fieldErrs []FieldError
+	for _, change := range report.Changes {
+		if !change.Breaking {
+			continue
+		}
+		message := change.Operation + ": "
+		if change.Location != "" {
+			message += change.Location + ": "
+		}
+		fieldErrs = append(fieldErrs, FieldError{Field: "spec", Message: message + change.Message})
+	}
+	return &Error{
+		Kind: ErrorKindUnprocessable,
+		Code: "breaking_change",
+		Message: fmt.Sprintf("spec has %d breaking change(s) compared to version %s, %s",
+			len(fieldErrs), report.BaseVersion, reason),
+		Fields: fieldErrs,
+	}
+}
@@ -0,0 +1,263 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gorm.io/gorm"
+)
+
+const CustomFieldSchemaTableName = "custom_field_schemas"
+
+// CustomFields contains the custom attributes of a Service, as a JSON object.
+type CustomFields map[string]interface{}
+
+// Value implements driver.Valuer, storing the fields as a JSON object.
+func (f CustomFields) Value() (driver.Value, error) {
+	if f == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (f *CustomFields) Scan(src interface{}) error {
+	return scanJSON(src, f)
+}
+
+// CustomFieldSchema represents a JSON Schema registered by an admin, which the
+// custom fields of all Services must conform to. Registering a schema creates a
+// new one; the latest schema is the one in use.
+type CustomFieldSchema struct {
+	ID        uint            `json:"id"`
+	Schema    json.RawMessage `json:"schema" gorm:"type:jsonb" swaggertype:"object"`
+	CreatedBy uint            `json:"createdBy"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+var (
+	ErrNoCustomFieldSchema = &Error{
+		Kind:    ErrorKindValidation,
+		Code:    "no_custom_field_schema",
+		Message: "custom fields are not allowed until a custom field schema is registered",
+	}
+	ErrCustomFieldSchemaViolated = &Error{
+		Kind:    ErrorKindConflict,
+		Code:    "custom_field_schema_violated",
+		Message: "the custom fields of existing services don't match the schema",
+	}
+)
+
+// compiledSchema caches the compiled version of the latest CustomFieldSchema.
+var compiledSchema struct {
+	sync.Mutex
+	id     uint
+	schema *jsonschema.Schema
+}
+
+// compileCustomFieldSchema compiles the JSON Schema. References to other documents
+// aren't resolved, so that registering a schema can't be used to read files or make
+// requests on behalf of the server.
+func compileCustomFieldSchema(schema json.RawMessage) (*jsonschema.Schema, error) {
+	const url = "urn:service-catalog:custom-fields"
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("unable to load %s: external references are not supported", s)
+	}
+	if err := compiler.AddResource(url, bytes.NewReader(schema)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(url)
+}
+
+// RegisterCustomFieldSchema compiles the JSON Schema and makes it the one custom
+// fields are validated against. The schema is rejected with
+// ErrCustomFieldSchemaViolated if the custom fields of any existing Service don't
+// conform to it.
+func RegisterCustomFieldSchema(ctx context.Context, schema json.RawMessage, userID uint) (*CustomFieldSchema, error) {
+	compiled, err := compileCustomFieldSchema(schema)
+	if err != nil {
+		return nil, &Error{
+			Kind:    ErrorKindValidation,
+			Code:    "invalid_custom_field_schema",
+			Message: fmt.Sprintf("invalid custom field schema: %s", err.Error()),
+		}
+	}
+
+	registered := &CustomFieldSchema{
+		Schema:    schema,
+		CreatedBy: userID,
+	}
+	err = DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Wait for the services whose custom fields are being validated against the
+		// current schema to be written, and keep others from being validated until the
+		// new schema is registered, see validateCustomFields. The lock conflicts with
+		// itself, so schemas are registered one at a time.
+		if err := tx.Exec(fmt.Sprintf("LOCK TABLE %s IN SHARE ROW EXCLUSIVE MODE", CustomFieldSchemaTableName)).Error; err != nil {
+			return err
+		}
+		rows, err := tx.Table(ServiceTableName).Select("id", "custom_fields").Order("id").Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var invalid []string
+		for rows.Next() {
+			var service Service
+			if err := tx.ScanRows(rows, &service); err != nil {
+				return err
+			}
+			if err := validateCustomFieldsWith(compiled, service.CustomFields); err != nil {
+				invalid = append(invalid, fmt.Sprint(service.ID))
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(invalid) > 0 {
+			return fmt.Errorf("%w: services %s", ErrCustomFieldSchemaViolated, strings.Join(invalid, ", "))
+		}
+		return tx.Table(CustomFieldSchemaTableName).Create(registered).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return registered, nil
+}
+
+// GetCustomFieldSchema returns the latest CustomFieldSchema.
+func GetCustomFieldSchema(ctx context.Context) (*CustomFieldSchema, error) {
+	return getCustomFieldSchema(DB.WithContext(ctx))
+}
+
+func getCustomFieldSchema(tx *gorm.DB) (*CustomFieldSchema, error) {
+	var schema CustomFieldSchema
+	if err := tx.Table(CustomFieldSchemaTableName).Order("id DESC").Limit(1).Find(&schema).Error; err != nil {
+		return nil, err
+	}
+	if schema.ID == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return &schema, nil
+}
+
+// validateCustomFields validates the fields against the latest CustomFieldSchema,
+// so that e.g. its required properties are enforced even if the fields are empty.
+// If no schema is registered, only empty fields are valid. It must be called inside
+// the transaction which writes the fields, since it keeps new schemas from being
+// registered until the end of the transaction, so that the fields are either
+// written before a new schema is checked against existing services or validated
+// against that schema.
+func validateCustomFields(tx *gorm.DB, fields CustomFields) error {
+	// SHARE mode doesn't conflict with itself, so concurrent writes of services
+	// don't block each other, only RegisterCustomFieldSchema.
+	if err := tx.Exec(fmt.Sprintf("LOCK TABLE %s IN SHARE MODE", CustomFieldSchemaTableName)).Error; err != nil {
+		return err
+	}
+	schema, err := getCustomFieldSchema(tx)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			if len(fields) == 0 {
+				return nil
+			}
+			return ErrNoCustomFieldSchema
+		}
+		return err
+	}
+
+	compiledSchema.Lock()
+	if compiledSchema.id != schema.ID {
+		compiled, err := compileCustomFieldSchema(schema.Schema)
+		if err != nil {
+			compiledSchema.Unlock()
+			return fmt.Errorf("unable to compile custom field schema %d: %w", schema.ID, err)
+		}
+		compiledSchema.id = schema.ID
+		compiledSchema.schema = compiled
+	}
+	compiled := compiledSchema.schema
+	compiledSchema.Unlock()
+
+	return validateCustomFieldsWith(compiled, fields)
+}
+
+func validateCustomFieldsWith(schema *jsonschema.Schema, fields CustomFields) error {
+	// The schema validates plain JSON values, so round trip the fields through JSON
+	// to make sure they only consist of such values.
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	var instance interface{}
+	if err := json.Unmarshal(b, &instance); err != nil {
+		return err
+	}
+	if instance == nil {
+		instance = map[string]interface{}{}
+	}
+
+	err = schema.Validate(instance)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	var fieldErrs []FieldError
+	collectCustomFieldErrors(validationErr, &fieldErrs)
+	messages := make([]string, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		messages = append(messages, fmt.Sprintf("%s: %s", fe.Field, fe.Message))
+	}
+	return NewValidationError("invalid custom fields: "+strings.Join(messages, "; "), fieldErrs...)
+}
+
+// collectCustomFieldErrors appends the innermost causes of the validation error,
+// which describe what exactly is wrong, to fieldErrs.
+func collectCustomFieldErrors(err *jsonschema.ValidationError, fieldErrs *[]FieldError) {
+	if len(err.Causes) == 0 {
+		*fieldErrs = append(*fieldErrs, FieldError{
+			Field:   "customFields" + strings.ReplaceAll(err.InstanceLocation, "/", "."),
+			Message: err.Message,
+		})
+		return
+	}
+	for _, cause := range err.Causes {
+		collectCustomFieldErrors(cause, fieldErrs)
+	}
+}
+
+// parseCustomFieldFilter parses a filter of the form 'key:value' into a JSON object
+// which can be used to match the custom fields of Services by containment. If the
+// value is valid JSON, e.g. a number or boolean, it's matched as such, otherwise
+// as a string.
+func parseCustomFieldFilter(filter string) (string, error) {
+	key, value, ok := strings.Cut(filter, ":")
+	if !ok || key == "" {
+		message := fmt.Sprintf("invalid custom field filter %q: must be of the form 'key:value'", filter)
+		return "", NewValidationError(message, FieldError{Field: "customField", Message: "must be of the form 'key:value'"})
+	}
+
+	var v interface{} = value
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+		v = parsed
+	}
+	b, err := json.Marshal(map[string]interface{}{key: v})
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
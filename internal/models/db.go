@@ -2,7 +2,8 @@ package models
 
 import (
 	"fmt"
-	"os"
+
+	"github.com/aryan9600/service-catalog/internal/config"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -18,46 +19,22 @@ const (
 
 var DB *gorm.DB
 
-var (
-	user       string
-	password   string
-	host       string
-	port       string
-	db         string
-	disableSSL string
-)
+// migrationsDirUri is the URI of the migrations last run by Migrate.
+var migrationsDirUri = MIGRATIONS_DIR_URI
 
-// SetDBConfiguration reads the database connection configuration from env vars.
+var dbConfig config.Database
+
+// SetDBConfiguration sets the database connection configuration.
 // This NEEDS to be called before InitDB().
-func SetDBConfiguration() error {
-	user = os.Getenv("POSTGRES_USER")
-	if user == "" {
-		return fmt.Errorf("unable to read env var POSTGRES_USER")
-	}
-	password = os.Getenv("POSTGRES_PASSWORD")
-	if password == "" {
-		return fmt.Errorf("unable to read env var POSTGRES_PASSWORD")
-	}
-	host = os.Getenv("POSTGRES_HOST")
-	if host == "" {
-		return fmt.Errorf("unable to read env var POSTGRES_HOST")
-	}
-	db = os.Getenv("POSTGRES_DB_NAME")
-	if db == "" {
-		return fmt.Errorf("unable to read env var POSTGRES_DB_NAME")
-	}
-	port = os.Getenv("POSTGRES_PORT")
-	if port == "" {
-		return fmt.Errorf("unable to read env var POSTGRES_PORT")
-	}
-	disableSSL = os.Getenv("POSTGRES_DISABLE_SSL")
-	return nil
+func SetDBConfiguration(cfg config.Database) {
+	dbConfig = cfg
 }
 
 // InitDB initializes the database handler.
 func InitDB() error {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s", host, user, password, db, port)
-	if disableSSL == "true" {
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s",
+		dbConfig.Host, dbConfig.User, dbConfig.Password, dbConfig.Name, dbConfig.Port)
+	if dbConfig.DisableSSL {
 		dsn = fmt.Sprintf("%s sslmode=disable", dsn)
 	}
 
@@ -69,18 +46,29 @@ func InitDB() error {
 	return nil
 }
 
+// CloseDB closes the connections of the database handler.
+func CloseDB() error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
 // Migrate runs the migrations present in the specified URI. If destroy is true,
 // the migrations are run downwards than upwards.
-func Migrate(migrationsDirUri string, destroy bool) error {
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", user, password, host, port, db)
-	if disableSSL == "true" {
+func Migrate(dirUri string, destroy bool) error {
+	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
+		dbConfig.User, dbConfig.Password, dbConfig.Host, dbConfig.Port, dbConfig.Name)
+	if dbConfig.DisableSSL {
 		connStr = fmt.Sprintf("%s?sslmode=disable", connStr)
 	}
 
-	if migrationsDirUri == "" {
-		migrationsDirUri = MIGRATIONS_DIR_URI
+	if dirUri == "" {
+		dirUri = MIGRATIONS_DIR_URI
 	}
-	m, err := migrate.New(migrationsDirUri, connStr)
+	migrationsDirUri = dirUri
+	m, err := migrate.New(dirUri, connStr)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/logging"
+	"gorm.io/gorm"
+)
+
+const DeploymentTableName = "deployments"
+
+// environments are the environments Versions can be deployed to, ordered from the
+// first to the last stage of a release.
+var environments = []string{"dev", "staging", "prod"}
+
+// SetEnvironmentConfig sets the environments, ordered from the first to the last
+// stage of a release.
+func SetEnvironmentConfig(cfg config.Deployments) {
+	environments = append([]string(nil), cfg.Environments...)
+}
+
+// Environments returns the configured environments in order.
+func Environments() []string {
+	return append([]string(nil), environments...)
+}
+
+// environmentIndex returns the position of the environment in the configured order.
+// Environments which are no longer configured come last.
+func environmentIndex(env string) int {
+	for i, e := range environments {
+		if e == env {
+			return i
+		}
+	}
+	return len(environments)
+}
+
+func validateEnvironment(env string) error {
+	if environmentIndex(env) < len(environments) {
+		return nil
+	}
+	message := fmt.Sprintf("must be one of: %s", strings.Join(environments, ", "))
+	return NewValidationError(fmt.Sprintf("invalid environment %q: %s", env, message), FieldError{Field: "environment", Message: message})
+}
+
+// Deployment represents a Version of a Service being deployed to an environment.
+type Deployment struct {
+	ID          uint      `json:"id"`
+	ServiceID   uint      `json:"serviceID"`
+	VersionID   uint      `json:"versionID"`
+	Version     string    `json:"version" gorm:"->"`
+	Environment string    `json:"environment"`
+	DeployedAt  time.Time `json:"deployedAt"`
+	DeployedBy  *uint     `json:"deployedBy"`
+	RequestID   *string   `json:"requestID"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// CreateDeploymentInput represents the input required to record a Deployment.
+type CreateDeploymentInput struct {
+	Version     string `json:"version" binding:"required,max=50"`
+	Environment string `json:"environment" binding:"required"`
+	// DeployedAt defaults to the current time.
+	DeployedAt *time.Time `json:"deployedAt"`
+	ServiceID  uint       `json:"-"`
+	UserID     uint       `json:"-"`
+}
+
+// CreateDeployment records that the Version of the Service was deployed to the environment.
+func CreateDeployment(ctx context.Context, input CreateDeploymentInput) (*Deployment, error) {
+	if err := validateEnvironment(input.Environment); err != nil {
+		return nil, err
+	}
+	deployedAt := time.Now()
+	if input.DeployedAt != nil {
+		if input.DeployedAt.After(deployedAt.Add(time.Minute)) {
+			return nil, NewValidationError("invalid deployedAt: must not be in the future",
+				FieldError{Field: "deployedAt", Message: "must not be in the future"})
+		}
+		deployedAt = *input.DeployedAt
+	}
+
+	deployment := &Deployment{
+		ServiceID:   input.ServiceID,
+		Version:     input.Version,
+		Environment: input.Environment,
+		DeployedAt:  deployedAt,
+		DeployedBy:  &input.UserID,
+		RequestID:   requestID(ctx),
+	}
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		v, err := getVersion(tx, input.ServiceID, input.UserID, input.Version)
+		if err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				if _, err := getService(tx, input.ServiceID, input.UserID); err != nil {
+					return err
+				}
+				message := fmt.Sprintf("service has no version %q", input.Version)
+				return NewValidationError(message, FieldError{Field: "version", Message: "must be an existing version of the service"})
+			}
+			return err
+		}
+		deployment.VersionID = v.ID
+		return tx.Table(DeploymentTableName).Create(deployment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.FromContext(ctx).Info().
+		Uint("service_id", deployment.ServiceID).
+		Str("version", deployment.Version).
+		Str("environment", deployment.Environment).
+		Msg("deployment recorded")
+	return deployment, nil
+}
+
+// ListDeploymentsInput represents the query parameters accepted while listing the
+// Deployments of a Service.
+type ListDeploymentsInput struct {
+	Environment string `form:"environment"`
+	Limit       int    `form:"limit" binding:"omitempty,min=1,max=1000"`
+	Offset      int    `form:"offset" binding:"omitempty,min=0"`
+	ServiceID   uint   `form:"-"`
+	UserID      uint   `form:"-"`
+}
+
+// ListDeployments returns the deployment history of the Service, newest first.
+func ListDeployments(ctx context.Context, input ListDeploymentsInput) ([]Deployment, error) {
+	if _, err := GetService(ctx, input.ServiceID, input.UserID); err != nil {
+		return nil, err
+	}
+	db := deploymentsQuery(DB.WithContext(ctx)).Where("deployments.service_id = ?", input.ServiceID)
+	if input.Environment != "" {
+		db = db.Where("deployments.environment = ?", input.Environment)
+	}
+	if input.Limit != 0 {
+		db = db.Limit(input.Limit).Offset(input.Offset)
+	}
+	deployments := make([]Deployment, 0)
+	if err := db.Order("deployments.deployed_at DESC, deployments.id DESC").Find(&deployments).Error; err != nil {
+		return nil, err
+	}
+	return deployments, nil
+}
+
+// CurrentDeployment represents the Version of a Service currently deployed to an environment.
+type CurrentDeployment struct {
+	Deployment
+	ServiceName string `json:"serviceName"`
+	ServiceSlug string `json:"serviceSlug"`
+}
+
+// ListCurrentDeploymentsInput represents the query parameters accepted while listing
+// what is currently deployed.
+type ListCurrentDeploymentsInput struct {
+	Environment string `form:"environment"`
+}
+
+// ListCurrentDeployments returns, for each Service in the catalog, the latest
+// Deployment to every environment, or only to the requested one. Results are
+// ordered by Service name and then in the order of the configured environments.
+func ListCurrentDeployments(ctx context.Context, input ListCurrentDeploymentsInput) ([]CurrentDeployment, error) {
+	latest := deploymentsQuery(DB.WithContext(ctx)).
+		Select("DISTINCT ON (deployments.service_id, deployments.environment) deployments.*, versions.version, services.name AS service_name, services.slug AS service_slug").
+		Joins(fmt.Sprintf("JOIN %s ON services.id=deployments.service_id", ServiceTableName)).
+		Order("deployments.service_id, deployments.environment, deployments.deployed_at DESC, deployments.id DESC")
+	if input.Environment != "" {
+		latest = latest.Where("deployments.environment = ?", input.Environment)
+	}
+
+	deployments := make([]CurrentDeployment, 0)
+	if err := latest.Find(&deployments).Error; err != nil {
+		return nil, err
+	}
+	sort.Slice(deployments, func(i, j int) bool {
+		a, b := deployments[i], deployments[j]
+		if a.ServiceName != b.ServiceName {
+			return a.ServiceName < b.ServiceName
+		}
+		if a.ServiceID != b.ServiceID {
+			return a.ServiceID < b.ServiceID
+		}
+		if ai, bi := environmentIndex(a.Environment), environmentIndex(b.Environment); ai != bi {
+			return ai < bi
+		}
+		return a.Environment < b.Environment
+	})
+	return deployments, nil
+}
+
+// deploymentsQuery selects Deployments along with their version strings.
+func deploymentsQuery(tx *gorm.DB) *gorm.DB {
+	return tx.Table(DeploymentTableName).
+		Select("deployments.*, versions.version").
+		Joins(fmt.Sprintf("JOIN %s ON versions.id=deployments.version_id", VersionTableName))
+}
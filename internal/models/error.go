@@ -1,8 +1,71 @@
 package models
 
-import "errors"
+import "strings"
+
+// ErrorKind classifies an Error by the way it should be reported to clients.
+type ErrorKind string
+
+const (
+	ErrorKindNotFound           ErrorKind = "not_found"
+	ErrorKindConflict           ErrorKind = "conflict"
+	ErrorKindValidation         ErrorKind = "validation"
+	ErrorKindUnprocessable      ErrorKind = "unprocessable"
+	ErrorKindForbidden          ErrorKind = "forbidden"
+	ErrorKindUnauthenticated    ErrorKind = "unauthenticated"
+	ErrorKindPreconditionFailed ErrorKind = "precondition_failed"
+)
+
+// Error represents a failure caused by the client's request rather than by the
+// system. Its message is safe to be shown to clients. Any other error returned
+// by this package should be treated as an internal error.
+type Error struct {
+	Kind ErrorKind
+	// Code is a stable, machine readable identifier of the error.
+	Code    string
+	Message string
+	// Fields contains the details of a validation error, if any.
+	Fields []FieldError
+}
+
+// FieldError describes why a single field of the input is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
 
 var (
-	ErrRecordNotFound            = errors.New("record not found")
-	ErrUniqueConstraintViolation = errors.New("unique key constraint violated")
+	ErrRecordNotFound            = &Error{Kind: ErrorKindNotFound, Code: "record_not_found", Message: "record not found"}
+	ErrUniqueConstraintViolation = &Error{Kind: ErrorKindConflict, Code: "unique_constraint_violation", Message: "unique key constraint violated"}
+	ErrInvalidSortKey            = &Error{Kind: ErrorKindValidation, Code: "invalid_sort_key", Message: "invalid sort key"}
+	ErrRowVersionMismatch        = &Error{Kind: ErrorKindPreconditionFailed, Code: "row_version_mismatch", Message: "row version mismatch"}
+	ErrUnauthenticated           = &Error{Kind: ErrorKindUnauthenticated, Code: "unauthenticated", Message: "Unauthenticated"}
+	ErrForbidden                 = &Error{Kind: ErrorKindForbidden, Code: "forbidden", Message: "forbidden"}
+	ErrServiceNameTaken          = &Error{Kind: ErrorKindConflict, Code: "service_name_taken", Message: "a service with this name already exists"}
+	ErrServiceSlugTaken          = &Error{Kind: ErrorKindConflict, Code: "service_slug_taken", Message: "this slug is or was used by another service"}
 )
+
+// NewValidationError returns an Error for an invalid input.
+func NewValidationError(message string, fields ...FieldError) *Error {
+	return &Error{
+		Kind:    ErrorKindValidation,
+		Code:    "invalid_input",
+		Message: message,
+		Fields:  fields,
+	}
+}
+
+// isUniqueConstraintViolation returns true if the database error was caused by a
+// violated unique constraint.
+func isUniqueConstraintViolation(err error) bool {
+	return strings.Contains(err.Error(), "duplicate key value violates unique constraint")
+}
+
+// violatesConstraint returns true if the database error was caused by the unique
+// constraint with the provided name.
+func violatesConstraint(err error, constraint string) bool {
+	return isUniqueConstraintViolation(err) && strings.Contains(err.Error(), `"`+constraint+`"`)
+}
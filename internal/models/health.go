@@ -0,0 +1,57 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// MigrationStatus compares the version of the database schema with the latest migration.
+type MigrationStatus struct {
+	Current uint `json:"current"`
+	Latest  uint `json:"latest"`
+	Dirty   bool `json:"dirty"`
+}
+
+// PingDB checks that a connection to the database can be established.
+func PingDB(ctx context.Context) error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// GetMigrationStatus returns the MigrationStatus of the database, comparing it
+// with the migrations last run by Migrate, or the default ones.
+func GetMigrationStatus(ctx context.Context) (*MigrationStatus, error) {
+	var status MigrationStatus
+	row := DB.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Row()
+	if err := row.Scan(&status.Current, &status.Dirty); err != nil {
+		return nil, fmt.Errorf("unable to read schema version: %w", err)
+	}
+
+	src, err := source.Open(migrationsDirUri)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+	defer src.Close()
+	version, err := src.First()
+	for err == nil {
+		status.Latest = version
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+	return &status, nil
+}
+
+// Pending returns whether the database schema is behind the latest migration, or
+// if the last migration failed.
+func (s MigrationStatus) Pending() bool {
+	return s.Dirty || s.Current < s.Latest
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ImportActionCreated   = "created"
+	ImportActionUpdated   = "updated"
+	ImportActionUnchanged = "unchanged"
+	ImportActionSkipped   = "skipped"
+)
+
+// errDryRun is returned from the import transaction to roll it back when
+// running in dry-run mode.
+var errDryRun = errors.New("dry run")
+
+// ImportServiceInput represents a single Service to be created or updated by
+// ImportServices.
+type ImportServiceInput struct {
+	Name        string
+	Description string
+	Owner       string
+	Tags        []string
+	Versions    []string
+	// Lifecycle is left unchanged if empty.
+	Lifecycle string
+}
+
+// ImportServiceResult represents the outcome of importing a single Service.
+type ImportServiceResult struct {
+	Name          string   `json:"name"`
+	Action        string   `json:"action"`
+	ServiceID     uint     `json:"serviceID,omitempty"`
+	AddedVersions []string `json:"addedVersions,omitempty"`
+	Reason        string   `json:"reason,omitempty"`
+}
+
+// ImportServices upserts the provided Services for the user. Existing Services are
+// matched by name; their description, owner and tags are overwritten and any
+// missing versions are added. Versions are never removed.
+// All changes are made in a single transaction. If dryRun is true, the transaction
+// is rolled back after computing the results.
+func ImportServices(ctx context.Context, inputs []ImportServiceInput, userID uint, dryRun bool) ([]ImportServiceResult, error) {
+	results := make([]ImportServiceResult, 0, len(inputs))
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, input := range inputs {
+			result, err := importService(tx, input, userID)
+			if err != nil {
+				return err
+			}
+			if dryRun && result.Action == ImportActionCreated {
+				// The ID only exists inside the transaction that is about to be rolled back.
+				result.ServiceID = 0
+			}
+			results = append(results, *result)
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return results, nil
+}
+
+func importService(tx *gorm.DB, input ImportServiceInput, userID uint) (*ImportServiceResult, error) {
+	// Names of existing Services are checked as well, so that importing a catalog
+	// reports names which no longer follow the rules.
+	if err := validateServiceName(input.Name); err != nil {
+		return nil, err
+	}
+
+	var service Service
+	db := tx.Table(ServiceTableName).Where("user_id = ?", userID).Where("name = ?", input.Name).Order("id")
+	if err := db.Limit(1).Find(&service).Error; err != nil {
+		return nil, err
+	}
+
+	result := &ImportServiceResult{Name: input.Name}
+	if service.ID == 0 {
+		// Imported services have no custom fields, which the schema may not allow.
+		if err := validateCustomFields(tx, nil); err != nil {
+			return nil, err
+		}
+		service = Service{
+			Name:        input.Name,
+			Slug:        Slugify(input.Name),
+			Description: input.Description,
+			Owner:       input.Owner,
+			Tags:        input.Tags,
+			Lifecycle:   input.Lifecycle,
+			UserID:      int(userID),
+		}
+		result.Action = ImportActionCreated
+	} else {
+		lifecycleUnchanged := input.Lifecycle == "" || service.Lifecycle == input.Lifecycle
+		if service.Description == input.Description && service.Owner == input.Owner && slices.Equal(service.Tags, input.Tags) && lifecycleUnchanged {
+			result.Action = ImportActionUnchanged
+		} else {
+			result.Action = ImportActionUpdated
+		}
+		service.Description = input.Description
+		service.Owner = input.Owner
+		service.Tags = input.Tags
+		if input.Lifecycle != "" {
+			service.Lifecycle = input.Lifecycle
+		}
+	}
+
+	for _, v := range input.Versions {
+		if slices.Contains(service.Versions, v) {
+			continue
+		}
+		service.Versions = append(service.Versions, v)
+		result.AddedVersions = append(result.AddedVersions, v)
+	}
+	if result.Action == ImportActionUnchanged && len(result.AddedVersions) > 0 {
+		result.Action = ImportActionUpdated
+	}
+
+	if result.Action != ImportActionUnchanged {
+		created := service.ID == 0
+		if !created {
+			service.RowVersion++
+		}
+		if err := tx.Table(ServiceTableName).Save(&service).Error; err != nil {
+			return nil, serviceConstraintError(err)
+		}
+		if created {
+			if err := reserveSlug(tx, &service); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, v := range result.AddedVersions {
+		version := Version{
+			Version:   v,
+			ServiceID: int(service.ID),
+		}
+		if err := tx.Table(VersionTableName).Create(&version).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	result.ServiceID = service.ID
+	return result, nil
+}
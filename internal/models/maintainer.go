@@ -0,0 +1,124 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ServiceMaintainerTableName = "service_maintainers"
+
+// Maintainer represents a user who, besides the owner of a Service, can approve
+// and promote its Versions.
+type Maintainer struct {
+	ServiceID uint      `json:"-"`
+	UserID    uint      `json:"userID"`
+	Username  string    `json:"username" gorm:"->"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SetMaintainersInput represents the input required to replace the maintainers of a Service.
+type SetMaintainersInput struct {
+	Usernames []string `json:"usernames" binding:"max=50,dive,required"`
+}
+
+// ListMaintainers returns the maintainers of the Service, ordered by username. The
+// Service must be owned or maintained by the user.
+func ListMaintainers(ctx context.Context, svcID uint, userID uint) ([]Maintainer, error) {
+	var service Service
+	db := maintainedBy(DB.WithContext(ctx).Table(ServiceTableName), userID).Where("services.id = ?", svcID)
+	if err := db.Find(&service).Error; err != nil {
+		return nil, err
+	}
+	if service.ID == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return listMaintainers(DB.WithContext(ctx), svcID)
+}
+
+func listMaintainers(tx *gorm.DB, svcID uint) ([]Maintainer, error) {
+	maintainers := make([]Maintainer, 0)
+	err := tx.Table(ServiceMaintainerTableName).
+		Select("service_maintainers.*, users.username").
+		Joins(fmt.Sprintf("JOIN %s ON users.id=service_maintainers.user_id", UserTableName)).
+		Where("service_maintainers.service_id = ?", svcID).
+		Order("users.username").
+		Find(&maintainers).Error
+	if err != nil {
+		return nil, err
+	}
+	return maintainers, nil
+}
+
+// SetMaintainers replaces the maintainers of the Service with the users with the
+// provided usernames. Only the owner of the Service can change its maintainers.
+func SetMaintainers(ctx context.Context, svcID uint, userID uint, input SetMaintainersInput) ([]Maintainer, error) {
+	var maintainers []Maintainer
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := getService(tx, svcID, userID); err != nil {
+			return err
+		}
+
+		usernames := make(map[string]bool)
+		for _, username := range input.Usernames {
+			usernames[username] = true
+		}
+		var users []User
+		if len(usernames) > 0 {
+			names := make([]string, 0, len(usernames))
+			for username := range usernames {
+				names = append(names, username)
+			}
+			if err := tx.Table(UserTableName).Where("username IN ?", names).Find(&users).Error; err != nil {
+				return err
+			}
+			for _, user := range users {
+				delete(usernames, user.Username)
+			}
+		}
+		if len(usernames) > 0 {
+			unknown := make([]string, 0, len(usernames))
+			for username := range usernames {
+				unknown = append(unknown, username)
+			}
+			sort.Strings(unknown)
+			message := fmt.Sprintf("unknown users: %s", strings.Join(unknown, ", "))
+			return NewValidationError(message, FieldError{Field: "usernames", Message: message})
+		}
+
+		if err := tx.Table(ServiceMaintainerTableName).Where("service_id = ?", svcID).Delete(&Maintainer{}).Error; err != nil {
+			return err
+		}
+		rows := make([]Maintainer, 0, len(users))
+		for _, user := range users {
+			// The owner is always a maintainer.
+			if user.ID == userID {
+				continue
+			}
+			rows = append(rows, Maintainer{ServiceID: svcID, UserID: user.ID})
+		}
+		if len(rows) > 0 {
+			if err := tx.Table(ServiceMaintainerTableName).Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+		var err error
+		maintainers, err = listMaintainers(tx, svcID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return maintainers, nil
+}
+
+// maintainedBy restricts the query, which must select from services, to the Services
+// owned or maintained by the user.
+func maintainedBy(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Where(fmt.Sprintf("(services.user_id = ? OR EXISTS (SELECT 1 FROM %s WHERE %s.service_id = services.id AND %s.user_id = ?))",
+		ServiceMaintainerTableName, ServiceMaintainerTableName, ServiceMaintainerTableName), userID, userID)
+}
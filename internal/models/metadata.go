@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Lifecycle stages of a Service.
+const (
+	LifecycleExperimental = "experimental"
+	LifecycleProduction   = "production"
+	LifecycleDeprecated   = "deprecated"
+)
+
+// ServiceLinks contains links to resources which help operating a Service.
+type ServiceLinks struct {
+	Runbook   string `json:"runbook,omitempty" binding:"omitempty,url,max=2048"`
+	Dashboard string `json:"dashboard,omitempty" binding:"omitempty,url,max=2048"`
+	Docs      string `json:"docs,omitempty" binding:"omitempty,url,max=2048"`
+}
+
+// Value implements driver.Valuer, storing the links as a JSON object.
+func (l ServiceLinks) Value() (driver.Value, error) {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (l *ServiceLinks) Scan(src interface{}) error {
+	return scanJSON(src, l)
+}
+
+// Contact represents a channel through which the people responsible for a
+// Service can be reached, e.g. a Slack channel or a PagerDuty service.
+type Contact struct {
+	Type  string `json:"type" binding:"required,oneof=email slack pagerduty opsgenie msteams phone url"`
+	Value string `json:"value" binding:"required,max=255"`
+}
+
+// Contacts is a list of Contact objects stored as a JSON array.
+type Contacts []Contact
+
+// Value implements driver.Valuer, storing the contacts as a JSON array.
+func (c Contacts) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (c *Contacts) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+// scanJSON decodes a JSON column into dest. NULL leaves dest untouched.
+func scanJSON(src interface{}, dest interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("unable to scan %T into %T", src, dest)
+	}
+}
@@ -0,0 +1,2 @@
+ALTER TABLE services DROP COLUMN IF EXISTS tags;
+ALTER TABLE services DROP COLUMN IF EXISTS owner;
@@ -0,0 +1,2 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS owner VARCHAR(255);
+ALTER TABLE services ADD COLUMN IF NOT EXISTS tags VARCHAR(50)[];
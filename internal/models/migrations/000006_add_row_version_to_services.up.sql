@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS row_version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS service_slugs;
+DROP TABLE IF EXISTS service_renames;
+ALTER TABLE services DROP CONSTRAINT IF EXISTS services_user_id_slug_key;
+ALTER TABLE services DROP CONSTRAINT IF EXISTS services_user_id_name_key;
+ALTER TABLE services DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,35 @@
+-- Names must be unique per user; suffix any existing duplicates with their ID.
+UPDATE services s SET name = LEFT(s.name, 200) || '-' || s.id
+WHERE EXISTS (SELECT 1 FROM services o WHERE o.user_id = s.user_id AND o.name = s.name AND o.id < s.id);
+
+ALTER TABLE services ADD COLUMN IF NOT EXISTS slug VARCHAR(63);
+UPDATE services SET slug = TRIM(BOTH '-' FROM LEFT(TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(name), '[^a-z0-9]+', '-', 'g')), 63));
+UPDATE services SET slug = 'service-' || id WHERE slug = '';
+UPDATE services s SET slug = TRIM(BOTH '-' FROM LEFT(s.slug, 62 - LENGTH(s.id::text))) || '-' || s.id
+WHERE EXISTS (SELECT 1 FROM services o WHERE o.user_id = s.user_id AND o.slug = s.slug AND o.id < s.id);
+ALTER TABLE services ALTER COLUMN slug SET NOT NULL;
+
+ALTER TABLE services ADD CONSTRAINT services_user_id_name_key UNIQUE (user_id, name);
+ALTER TABLE services ADD CONSTRAINT services_user_id_slug_key UNIQUE (user_id, slug);
+
+CREATE TABLE IF NOT EXISTS service_renames (
+    id SERIAL PRIMARY KEY,
+    service_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    old_name VARCHAR(255) NOT NULL,
+    new_name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (service_id) REFERENCES services(id) ON DELETE CASCADE
+);
+
+-- Every slug handed out to a service, so that it is never handed out again.
+CREATE TABLE IF NOT EXISTS service_slugs (
+    id SERIAL PRIMARY KEY,
+    service_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    slug VARCHAR(63) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (service_id) REFERENCES services(id) ON DELETE CASCADE,
+    CONSTRAINT service_slugs_user_id_slug_key UNIQUE (user_id, slug)
+);
+INSERT INTO service_slugs (service_id, user_id, slug) SELECT id, user_id, slug FROM services;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS services_contacts;
+DROP INDEX IF EXISTS services_lifecycle;
+ALTER TABLE services DROP COLUMN IF EXISTS contacts;
+ALTER TABLE services DROP COLUMN IF EXISTS links;
+ALTER TABLE services DROP COLUMN IF EXISTS repository_url;
+ALTER TABLE services DROP COLUMN IF EXISTS tier;
+ALTER TABLE services DROP COLUMN IF EXISTS lifecycle;
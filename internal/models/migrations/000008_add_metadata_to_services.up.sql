@@ -0,0 +1,10 @@
+ALTER TABLE services ADD COLUMN IF NOT EXISTS lifecycle VARCHAR(20) NOT NULL DEFAULT 'production'
+    CHECK (lifecycle IN ('experimental', 'production', 'deprecated'));
+ALTER TABLE services ADD COLUMN IF NOT EXISTS tier SMALLINT NOT NULL DEFAULT 0
+    CHECK (tier BETWEEN 0 AND 4);
+ALTER TABLE services ADD COLUMN IF NOT EXISTS repository_url VARCHAR(2048) NOT NULL DEFAULT '';
+ALTER TABLE services ADD COLUMN IF NOT EXISTS links JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE services ADD COLUMN IF NOT EXISTS contacts JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS services_lifecycle ON services (lifecycle);
+CREATE INDEX IF NOT EXISTS services_contacts ON services USING GIN (contacts);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS services_custom_fields;
+ALTER TABLE services DROP COLUMN IF EXISTS custom_fields;
+DROP TABLE IF EXISTS custom_field_schemas;
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS spec_blobs;
+DROP TABLE IF EXISTS version_specs;
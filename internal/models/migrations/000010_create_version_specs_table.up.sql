@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS version_specs (
+    id SERIAL PRIMARY KEY,
+    version_id INTEGER NOT NULL UNIQUE,
+    format VARCHAR(20) NOT NULL,
+    encoding VARCHAR(20) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size INTEGER NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (version_id) REFERENCES versions(id) ON DELETE CASCADE
+);
+
+-- Used when specs are stored in the database instead of the filesystem.
+CREATE TABLE IF NOT EXISTS spec_blobs (
+    key VARCHAR(255) PRIMARY KEY,
+    content BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS compatibility_reports;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS compatibility_reports (
+    id SERIAL PRIMARY KEY,
+    version_id INTEGER NOT NULL UNIQUE,
+    base_version_id INTEGER NOT NULL,
+    breaking BOOLEAN NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (version_id) REFERENCES versions(id) ON DELETE CASCADE,
+    FOREIGN KEY (base_version_id) REFERENCES versions(id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+ALTER TABLE versions ADD COLUMN changelog_text TEXT;
+UPDATE versions SET changelog_text = (
+    SELECT string_agg(entry->>'description', E'\n' ORDER BY ordinality)
+    FROM jsonb_array_elements(versions.changelog) WITH ORDINALITY AS entries(entry, ordinality)
+);
+ALTER TABLE versions DROP COLUMN changelog;
+ALTER TABLE versions RENAME COLUMN changelog_text TO changelog;
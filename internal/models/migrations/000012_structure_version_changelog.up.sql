@@ -0,0 +1,8 @@
+-- Existing free text changelogs become a single 'changed' entry.
+ALTER TABLE versions ALTER COLUMN changelog TYPE JSONB USING
+    CASE
+        WHEN changelog IS NULL OR btrim(changelog) = '' THEN '[]'::jsonb
+        ELSE jsonb_build_array(jsonb_build_object('type', 'changed', 'description', changelog))
+    END;
+ALTER TABLE versions ALTER COLUMN changelog SET DEFAULT '[]';
+ALTER TABLE versions ALTER COLUMN changelog SET NOT NULL;
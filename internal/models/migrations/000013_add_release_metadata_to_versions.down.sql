@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS versions_release_image_digest;
+DROP INDEX IF EXISTS versions_release_commit;
+
+ALTER TABLE versions DROP COLUMN IF EXISTS release_artifacts;
+ALTER TABLE versions DROP COLUMN IF EXISTS release_image_digest;
+ALTER TABLE versions DROP COLUMN IF EXISTS release_build_time;
+ALTER TABLE versions DROP COLUMN IF EXISTS release_commit;
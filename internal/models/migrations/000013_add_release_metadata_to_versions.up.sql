@@ -0,0 +1,10 @@
+ALTER TABLE versions ADD COLUMN IF NOT EXISTS release_commit VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE versions ADD COLUMN IF NOT EXISTS release_build_time TIMESTAMP WITH TIME ZONE;
+ALTER TABLE versions ADD COLUMN IF NOT EXISTS release_image_digest VARCHAR(135) NOT NULL DEFAULT '';
+ALTER TABLE versions ADD COLUMN IF NOT EXISTS release_artifacts JSONB NOT NULL DEFAULT '[]';
+
+-- Commits are looked up by prefix, image digests by their exact value.
+CREATE INDEX IF NOT EXISTS versions_release_commit ON versions (release_commit text_pattern_ops)
+    WHERE release_commit <> '';
+CREATE INDEX IF NOT EXISTS versions_release_image_digest ON versions (release_image_digest)
+    WHERE release_image_digest <> '';
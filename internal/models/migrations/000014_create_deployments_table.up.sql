@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS deployments (
+    id SERIAL PRIMARY KEY,
+    service_id INTEGER NOT NULL,
+    version_id INTEGER NOT NULL,
+    environment VARCHAR(50) NOT NULL,
+    deployed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deployed_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (service_id) REFERENCES services(id) ON DELETE CASCADE,
+    FOREIGN KEY (version_id) REFERENCES versions(id) ON DELETE CASCADE,
+    FOREIGN KEY (deployed_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Serves both the history of an environment and the latest deployment per environment.
+CREATE INDEX IF NOT EXISTS deployments_service_environment ON deployments (service_id, environment, deployed_at DESC, id DESC);
@@ -0,0 +1,4 @@
+ALTER TABLE promotion_approvals DROP COLUMN IF EXISTS request_id;
+ALTER TABLE promotions DROP COLUMN IF EXISTS request_id;
+ALTER TABLE deployments DROP COLUMN IF EXISTS request_id;
+ALTER TABLE service_renames DROP COLUMN IF EXISTS request_id;
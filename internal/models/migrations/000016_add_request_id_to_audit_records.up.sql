@@ -0,0 +1,5 @@
+-- The ID of the request which created the record, to correlate it with log lines.
+ALTER TABLE service_renames ADD COLUMN IF NOT EXISTS request_id VARCHAR(128);
+ALTER TABLE deployments ADD COLUMN IF NOT EXISTS request_id VARCHAR(128);
+ALTER TABLE promotions ADD COLUMN IF NOT EXISTS request_id VARCHAR(128);
+ALTER TABLE promotion_approvals ADD COLUMN IF NOT EXISTS request_id VARCHAR(128);
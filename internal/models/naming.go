@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+)
+
+const (
+	// SlugMaxLength is the maximum length of a slug, which is the same as that of a DNS label.
+	SlugMaxLength = 63
+)
+
+var (
+	slugPattern        = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	slugInvalidSubstrs = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// ServiceNameRules represents the rules a Service's name must follow.
+type ServiceNameRules struct {
+	MaxLength int
+	// Pattern, if set, must be matched by the whole name.
+	Pattern *regexp.Regexp
+}
+
+var serviceNameRules = ServiceNameRules{MaxLength: 50}
+
+// SetServiceNamingConfig sets the rules for Service names. If it isn't called, names
+// can contain any character and be at most 50 characters long.
+func SetServiceNamingConfig(cfg config.Services) error {
+	rules := ServiceNameRules{MaxLength: cfg.NameMaxLength}
+	if cfg.NamePattern != "" {
+		re, err := regexp.Compile(cfg.NamePattern)
+		if err != nil {
+			return fmt.Errorf("invalid service name pattern: %w", err)
+		}
+		rules.Pattern = re
+	}
+	serviceNameRules = rules
+	return nil
+}
+
+// validateServiceName checks the name against the configured rules and makes
+// sure that a slug can be derived from it.
+func validateServiceName(name string) error {
+	var message string
+	switch {
+	case name == "":
+		message = "is required"
+	case len(name) > serviceNameRules.MaxLength:
+		message = fmt.Sprintf("must be at most %d characters long", serviceNameRules.MaxLength)
+	case serviceNameRules.Pattern != nil && !serviceNameRules.Pattern.MatchString(name):
+		message = fmt.Sprintf("must match the pattern %s", serviceNameRules.Pattern.String())
+	case Slugify(name) == "":
+		message = "must contain at least one letter or digit"
+	default:
+		return nil
+	}
+	return NewValidationError(fmt.Sprintf("invalid service name: %s", message), FieldError{Field: "name", Message: message})
+}
+
+// validateSlug checks if the slug is a valid DNS label.
+func validateSlug(slug string) error {
+	if len(slug) <= SlugMaxLength && slugPattern.MatchString(slug) {
+		return nil
+	}
+	message := fmt.Sprintf("must be a DNS label, i.e. at most %d lowercase letters, digits or '-', starting and ending with a letter or digit", SlugMaxLength)
+	return NewValidationError(fmt.Sprintf("invalid service slug: %s", message), FieldError{Field: "slug", Message: message})
+}
+
+// Slugify derives a slug from a Service's name by lowercasing it and replacing
+// every run of characters other than letters and digits with a '-'. The result
+// is a valid DNS label, unless the name doesn't contain any letters or digits,
+// in which case it is empty.
+func Slugify(name string) string {
+	slug := slugInvalidSubstrs.ReplaceAllString(strings.ToLower(name), "-")
+	slug = strings.Trim(slug, "-")
+	if len(slug) > SlugMaxLength {
+		slug = strings.TrimRight(slug[:SlugMaxLength], "-")
+	}
+	return slug
+}
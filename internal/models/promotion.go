@@ -0,0 +1,342 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/logging"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	PromotionTableName         = "promotions"
+	PromotionApprovalTableName = "promotion_approvals"
+)
+
+// PromotionRules are the rules a Version must satisfy to be promoted to a stage.
+// Stages are the configured environments, in order.
+type PromotionRules struct {
+	// MinSoakTime is, per stage, how long a Version must have been in the previous
+	// stage before it can be promoted to the stage.
+	MinSoakTime map[string]time.Duration
+	// RequiredApprovals is, per stage, the number of maintainers who must approve
+	// the promotion of a Version to the stage.
+	RequiredApprovals map[string]int
+}
+
+var promotionRules = PromotionRules{
+	MinSoakTime:       map[string]time.Duration{},
+	RequiredApprovals: map[string]int{},
+}
+
+// SetPromotionConfig sets the PromotionRules. Stages without a soak time or a number
+// of approvals don't require any.
+func SetPromotionConfig(cfg config.Promotion) {
+	rules := PromotionRules{
+		MinSoakTime:       map[string]time.Duration{},
+		RequiredApprovals: map[string]int{},
+	}
+	for stage, d := range cfg.MinSoakTime {
+		rules.MinSoakTime[stage] = d
+	}
+	for stage, n := range cfg.RequiredApprovals {
+		rules.RequiredApprovals[stage] = n
+	}
+	promotionRules = rules
+}
+
+// Promotion represents a Version being promoted to a stage.
+type Promotion struct {
+	ID         uint      `json:"-"`
+	VersionID  uint      `json:"-"`
+	Stage      string    `json:"stage"`
+	PromotedBy *uint     `json:"promotedBy"`
+	PromotedAt time.Time `json:"promotedAt"`
+	RequestID  *string   `json:"requestID"`
+}
+
+// PromotionApproval represents a maintainer approving the promotion of a Version to a stage.
+type PromotionApproval struct {
+	ID        uint      `json:"-"`
+	VersionID uint      `json:"-"`
+	Stage     string    `json:"stage"`
+	UserID    uint      `json:"userID"`
+	Username  string    `json:"username" gorm:"->"`
+	RequestID *string   `json:"requestID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PromotionState represents how far a Version has been promoted, and what is
+// required to promote it to the next stage.
+type PromotionState struct {
+	Version string `json:"version"`
+	// Stage is the latest stage the Version was promoted to, or empty.
+	Stage string `json:"stage"`
+	// NextStage is empty once the Version reached the last stage.
+	NextStage  string      `json:"nextStage,omitempty"`
+	Promotions []Promotion `json:"promotions"`
+	// Approvals are the approvals for the promotion to NextStage.
+	Approvals         []PromotionApproval `json:"approvals"`
+	RequiredApprovals int                 `json:"requiredApprovals"`
+	// Blockers are the reasons why the Version can't be promoted to NextStage right now.
+	Blockers []string `json:"blockers"`
+}
+
+// PromoteVersionInput represents the input required to promote a Version.
+type PromoteVersionInput struct {
+	// Stage defaults to the next stage of the Version.
+	Stage string `json:"stage"`
+}
+
+// ApprovePromotionInput represents the input required to approve the promotion of a Version.
+type ApprovePromotionInput struct {
+	// Stage defaults to the next stage of the Version.
+	Stage string `json:"stage"`
+}
+
+// GetPromotionState returns the PromotionState of the Version of the Service, which
+// must be owned or maintained by the user.
+func GetPromotionState(ctx context.Context, svcID uint, userID uint, version string) (*PromotionState, error) {
+	var state *PromotionState
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		v, err := getMaintainedVersion(tx, svcID, userID, version)
+		if err != nil {
+			return err
+		}
+		state, err = getPromotionState(tx, v, time.Now(), 0)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// PromoteVersion promotes the Version of the Service to the requested stage, or
+// the next one. The promotion is rejected with a conflict unless the stage
+// directly follows the Version's current one, the Version soaked long enough in
+// its current stage and enough maintainers approved the promotion.
+func PromoteVersion(ctx context.Context, svcID uint, userID uint, version string, input PromoteVersionInput) (*PromotionState, error) {
+	var state *PromotionState
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		v, err := getMaintainedVersion(tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: VersionTableName}}), svcID, userID, version)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		current, err := getPromotionState(tx, v, now, userID)
+		if err != nil {
+			return err
+		}
+		stage, err := promotionStage(current, input.Stage)
+		if err != nil {
+			return err
+		}
+		if stage != current.NextStage {
+			return promotionRejected(stage, []string{stageOrderBlocker(current, stage)})
+		}
+		if len(current.Blockers) > 0 {
+			return promotionRejected(stage, current.Blockers)
+		}
+
+		promotion := &Promotion{
+			VersionID:  v.ID,
+			Stage:      stage,
+			PromotedBy: &userID,
+			PromotedAt: now,
+			RequestID:  requestID(ctx),
+		}
+		if err := tx.Table(PromotionTableName).Create(promotion).Error; err != nil {
+			return err
+		}
+		state, err = getPromotionState(tx, v, now, 0)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.FromContext(ctx).Info().
+		Uint("service_id", svcID).
+		Str("version", version).
+		Str("stage", state.Stage).
+		Msg("version promoted")
+	return state, nil
+}
+
+// ApprovePromotion records the user's approval of the promotion of the Version of
+// the Service to the requested stage, or the next one. Approving twice has no effect.
+func ApprovePromotion(ctx context.Context, svcID uint, userID uint, version string, input ApprovePromotionInput) (*PromotionState, error) {
+	var state *PromotionState
+	var approved string
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		v, err := getMaintainedVersion(tx, svcID, userID, version)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		current, err := getPromotionState(tx, v, now, 0)
+		if err != nil {
+			return err
+		}
+		stage, err := promotionStage(current, input.Stage)
+		if err != nil {
+			return err
+		}
+		if current.Stage != "" && environmentIndex(stage) <= environmentIndex(current.Stage) {
+			message := fmt.Sprintf("version was already promoted to %s", stage)
+			return NewValidationError(message, FieldError{Field: "stage", Message: message})
+		}
+
+		approved = stage
+		approval := &PromotionApproval{VersionID: v.ID, Stage: stage, UserID: userID, RequestID: requestID(ctx)}
+		err = tx.Table(PromotionApprovalTableName).Clauses(clause.OnConflict{DoNothing: true}).Create(approval).Error
+		if err != nil {
+			return err
+		}
+		state, err = getPromotionState(tx, v, now, 0)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.FromContext(ctx).Info().
+		Uint("service_id", svcID).
+		Str("version", version).
+		Str("stage", approved).
+		Uint("user_id", userID).
+		Msg("promotion approved")
+	return state, nil
+}
+
+// promotionStage returns the requested stage, defaulting to the next one.
+func promotionStage(state *PromotionState, stage string) (string, error) {
+	if stage == "" {
+		if state.NextStage == "" {
+			message := fmt.Sprintf("version was already promoted to the last stage, %s", state.Stage)
+			return "", NewValidationError(message, FieldError{Field: "stage", Message: message})
+		}
+		return state.NextStage, nil
+	}
+	if environmentIndex(stage) == len(environments) {
+		message := fmt.Sprintf("must be one of: %s", strings.Join(environments, ", "))
+		return "", NewValidationError(fmt.Sprintf("invalid stage %q: %s", stage, message), FieldError{Field: "stage", Message: message})
+	}
+	return stage, nil
+}
+
+func stageOrderBlocker(state *PromotionState, stage string) string {
+	switch {
+	case state.Stage != "" && environmentIndex(stage) <= environmentIndex(state.Stage):
+		return fmt.Sprintf("version was already promoted to %s", stage)
+	case state.NextStage == "":
+		return "version was already promoted to the last stage"
+	default:
+		return fmt.Sprintf("version must be promoted to %s before %s", state.NextStage, stage)
+	}
+}
+
+// promotionRejected returns an Error listing the reasons why the promotion was rejected.
+func promotionRejected(stage string, blockers []string) error {
+	fieldErrs := make([]FieldError, 0, len(blockers))
+	for _, blocker := range blockers {
+		fieldErrs = append(fieldErrs, FieldError{Field: "stage", Message: blocker})
+	}
+	return &Error{
+		Kind:    ErrorKindConflict,
+		Code:    "promotion_rejected",
+		Message: fmt.Sprintf("promotion to %s rejected: %s", stage, strings.Join(blockers, "; ")),
+		Fields:  fieldErrs,
+	}
+}
+
+// getPromotionState loads the promotions and approvals of the Version and evaluates
+// the PromotionRules for its next stage at the provided time. If promoter isn't 0,
+// the rules are evaluated for a promotion by that user, whose own approval doesn't
+// count towards the required ones.
+func getPromotionState(tx *gorm.DB, v *Version, now time.Time, promoter uint) (*PromotionState, error) {
+	state := &PromotionState{
+		Version:    v.Version,
+		Promotions: make([]Promotion, 0),
+		Approvals:  make([]PromotionApproval, 0),
+		Blockers:   make([]string, 0),
+	}
+	if err := tx.Table(PromotionTableName).Where("version_id = ?", v.ID).Order("promoted_at, id").Find(&state.Promotions).Error; err != nil {
+		return nil, err
+	}
+
+	// Promotions to stages which are no longer configured are ignored.
+	current := -1
+	var promotedAt time.Time
+	for _, p := range state.Promotions {
+		if i := environmentIndex(p.Stage); i < len(environments) && i > current {
+			current = i
+			promotedAt = p.PromotedAt
+		}
+	}
+	if current >= 0 {
+		state.Stage = environments[current]
+	}
+	if current+1 >= len(environments) {
+		return state, nil
+	}
+	state.NextStage = environments[current+1]
+
+	// Only approvals of users who still own or maintain the Service count.
+	err := tx.Table(PromotionApprovalTableName).
+		Select("promotion_approvals.*, users.username").
+		Joins(fmt.Sprintf("JOIN %s ON users.id=promotion_approvals.user_id", UserTableName)).
+		Joins(fmt.Sprintf("JOIN %s ON versions.id=promotion_approvals.version_id", VersionTableName)).
+		Joins(fmt.Sprintf("JOIN %s ON services.id=versions.service_id", ServiceTableName)).
+		Where("promotion_approvals.version_id = ? AND promotion_approvals.stage = ?", v.ID, state.NextStage).
+		Where(fmt.Sprintf("(services.user_id = promotion_approvals.user_id OR EXISTS (SELECT 1 FROM %s WHERE %s.service_id = services.id AND %s.user_id = promotion_approvals.user_id))",
+			ServiceMaintainerTableName, ServiceMaintainerTableName, ServiceMaintainerTableName)).
+		Order("promotion_approvals.created_at, promotion_approvals.id").
+		Find(&state.Approvals).Error
+	if err != nil {
+		return nil, err
+	}
+	state.RequiredApprovals = promotionRules.RequiredApprovals[state.NextStage]
+
+	if soak := promotionRules.MinSoakTime[state.NextStage]; current >= 0 && soak > 0 {
+		if left := promotedAt.Add(soak).Sub(now); left > 0 {
+			state.Blockers = append(state.Blockers, fmt.Sprintf("version must soak in %s for %s before being promoted to %s; %s left",
+				state.Stage, soak, state.NextStage, left.Round(time.Second)))
+		}
+	}
+	approvals := 0
+	for _, a := range state.Approvals {
+		if a.UserID != promoter {
+			approvals++
+		}
+	}
+	if approvals < state.RequiredApprovals {
+		blocker := fmt.Sprintf("promotion to %s requires %d approval(s) from maintainers, got %d",
+			state.NextStage, state.RequiredApprovals, approvals)
+		if approvals < len(state.Approvals) {
+			blocker += "; users can't approve their own promotions"
+		}
+		state.Blockers = append(state.Blockers, blocker)
+	}
+	return state, nil
+}
+
+// getMaintainedVersion returns the Version of the Service, which must be owned or
+// maintained by the user.
+func getMaintainedVersion(tx *gorm.DB, svcID uint, userID uint, version string) (*Version, error) {
+	var v Version
+	db := tx.Table(VersionTableName).Select("versions.*")
+	db = db.Joins(fmt.Sprintf("JOIN %s ON services.id=versions.service_id", ServiceTableName))
+	db = db.Where("versions.service_id = ?", svcID).Where("versions.version = ?", version)
+	db = maintainedBy(db, userID)
+	if err := db.Find(&v).Error; err != nil {
+		return nil, err
+	}
+	if v.ID == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return &v, nil
+}
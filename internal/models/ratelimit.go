@@ -0,0 +1,77 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aryan9600/service-catalog/internal/ratelimit"
+	"gorm.io/gorm"
+)
+
+const RateLimitBucketTableName = "rate_limit_buckets"
+
+// rateLimitSweepInterval is how often the buckets which are full are deleted.
+const rateLimitSweepInterval = time.Minute
+
+// databaseRateLimiter is a ratelimit.Limiter which keeps buckets in the
+// rate_limit_buckets table, so that all replicas share them. Buckets are updated
+// with the clock of the database, so that the clocks of the replicas don't matter.
+type databaseRateLimiter struct {
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewDatabaseRateLimiter returns a ratelimit.Limiter shared by all replicas using the database.
+func NewDatabaseRateLimiter() ratelimit.Limiter {
+	return &databaseRateLimiter{}
+}
+
+type rateLimitBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+	Now       time.Time
+}
+
+func (l *databaseRateLimiter) Allow(ctx context.Context, key string, policy ratelimit.Policy) (ratelimit.Result, error) {
+	key = policy.Name + ":" + key
+	var result ratelimit.Result
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("INSERT INTO "+RateLimitBucketTableName+" (key, tokens, updated_at, full_at) VALUES (?, ?, now(), now()) "+
+			"ON CONFLICT (key) DO NOTHING", key, policy.Burst).Error
+		if err != nil {
+			return err
+		}
+		var row rateLimitBucket
+		err = tx.Raw("SELECT tokens, updated_at, now() AS now FROM "+RateLimitBucketTableName+" WHERE key = ? FOR UPDATE", key).
+			Scan(&row).Error
+		if err != nil {
+			return err
+		}
+
+		bucket := ratelimit.Bucket{Tokens: row.Tokens, Updated: row.UpdatedAt}
+		result = bucket.Take(policy, row.Now)
+		return tx.Exec("UPDATE "+RateLimitBucketTableName+" SET tokens = ?, updated_at = ?, full_at = ? WHERE key = ?",
+			bucket.Tokens, bucket.Updated, bucket.FullAt(policy), key).Error
+	})
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	l.sweep(ctx)
+	return result, nil
+}
+
+// sweep deletes the buckets which are full, since they are the same as new ones.
+func (l *databaseRateLimiter) sweep(ctx context.Context) {
+	l.mu.Lock()
+	due := time.Since(l.lastSweep) >= rateLimitSweepInterval
+	if due {
+		l.lastSweep = time.Now()
+	}
+	l.mu.Unlock()
+	if !due {
+		return
+	}
+	// Failing to sweep only leaves unused rows behind until the next sweep.
+	_ = DB.WithContext(ctx).Exec("DELETE FROM " + RateLimitBucketTableName + " WHERE full_at < now()").Error
+}
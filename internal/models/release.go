@@ -0,0 +1,126 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// commitPrefixMinLength is the minimum length of a commit SHA prefix used for lookups.
+	commitPrefixMinLength = 7
+	// maxVersionLookupResults is the maximum number of Versions returned by a lookup.
+	maxVersionLookupResults = 100
+)
+
+var (
+	// commitPattern matches full SHA-1 and SHA-256 git commit hashes.
+	commitPattern       = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+	commitPrefixPattern = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+	// imageDigestPattern matches OCI content digests using a registered algorithm.
+	imageDigestPattern = regexp.MustCompile(`^(sha256:[0-9a-f]{64}|sha512:[0-9a-f]{128})$`)
+)
+
+// ReleaseMetadata describes how a Version was built and where its artifacts are.
+type ReleaseMetadata struct {
+	// Commit is the full SHA of the git commit the Version was built from.
+	Commit    string     `json:"commit,omitempty"`
+	BuildTime *time.Time `json:"buildTime,omitempty"`
+	// ImageDigest is the digest of the container image, e.g. 'sha256:<hex>'.
+	ImageDigest string    `json:"imageDigest,omitempty"`
+	Artifacts   Artifacts `json:"artifacts,omitempty" gorm:"type:jsonb" binding:"max=50,dive"`
+}
+
+// Artifact represents a file published for a Version, like a binary or a chart.
+type Artifact struct {
+	Name string `json:"name" binding:"required,max=255"`
+	URL  string `json:"url" binding:"required,url,max=2048"`
+}
+
+// Artifacts is a list of Artifact objects stored as a JSON array.
+type Artifacts []Artifact
+
+// Value implements driver.Valuer, storing the artifacts as a JSON array.
+func (a Artifacts) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]Artifact(a))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (a *Artifacts) Scan(src interface{}) error {
+	return scanJSON(src, a)
+}
+
+// normalizeRelease lowercases the hashes in the ReleaseMetadata and validates them.
+func normalizeRelease(release *ReleaseMetadata) error {
+	release.Commit = strings.ToLower(release.Commit)
+	release.ImageDigest = strings.ToLower(release.ImageDigest)
+
+	var fieldErrs []FieldError
+	if release.Commit != "" && !commitPattern.MatchString(release.Commit) {
+		fieldErrs = append(fieldErrs, FieldError{Field: "release.commit", Message: "must be a full SHA-1 or SHA-256 commit hash"})
+	}
+	if release.ImageDigest != "" && !imageDigestPattern.MatchString(release.ImageDigest) {
+		fieldErrs = append(fieldErrs, FieldError{Field: "release.imageDigest", Message: "must be a digest of the form 'sha256:<64 hex digits>' or 'sha512:<128 hex digits>'"})
+	}
+	if len(fieldErrs) > 0 {
+		return NewValidationError("invalid release metadata", fieldErrs...)
+	}
+	return nil
+}
+
+// FindVersionsInput represents the query parameters accepted while looking up
+// Versions by their release metadata. Exactly one of Commit and ImageDigest must be set.
+type FindVersionsInput struct {
+	// Commit may be a prefix of at least 7 characters.
+	Commit      string `form:"commit"`
+	ImageDigest string `form:"imageDigest"`
+}
+
+// ServiceVersion represents a Version along with the Service it belongs to.
+type ServiceVersion struct {
+	Version
+	ServiceName string `json:"serviceName"`
+	ServiceSlug string `json:"serviceSlug"`
+}
+
+// FindVersions returns the Versions, across all Services in the catalog, which were
+// built from the commit or published as the container image. At most 100 Versions
+// are returned, oldest first.
+func FindVersions(ctx context.Context, input FindVersionsInput) ([]ServiceVersion, error) {
+	commit := strings.ToLower(input.Commit)
+	digest := strings.ToLower(input.ImageDigest)
+	switch {
+	case (commit == "") == (digest == ""):
+		return nil, NewValidationError("exactly one of 'commit' and 'imageDigest' is required")
+	case commit != "" && !commitPrefixPattern.MatchString(commit):
+		message := fmt.Sprintf("must be a commit hash or a prefix of at least %d hex digits", commitPrefixMinLength)
+		return nil, NewValidationError("invalid commit: "+message, FieldError{Field: "commit", Message: message})
+	case digest != "" && !imageDigestPattern.MatchString(digest):
+		message := "must be a digest of the form 'sha256:<64 hex digits>' or 'sha512:<128 hex digits>'"
+		return nil, NewValidationError("invalid imageDigest: "+message, FieldError{Field: "imageDigest", Message: message})
+	}
+
+	versions := make([]ServiceVersion, 0)
+	db := DB.WithContext(ctx).Table(VersionTableName).Select("versions.*", "services.name AS service_name", "services.slug AS service_slug")
+	db = db.Joins(fmt.Sprintf("JOIN %s ON services.id=versions.service_id", ServiceTableName))
+	if commit != "" {
+		db = db.Where("versions.release_commit LIKE ?", commit+"%")
+	} else {
+		db = db.Where("versions.release_image_digest = ?", digest)
+	}
+	if err := db.Order("versions.id").Limit(maxVersionLookupResults).Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ServiceRenameTableName = "service_renames"
+	ServiceSlugTableName   = "service_slugs"
+)
+
+// ServiceRename records a change of a Service's name. The slug of a Service never
+// changes, so renames don't affect it.
+type ServiceRename struct {
+	ID        uint      `json:"id"`
+	ServiceID uint      `json:"serviceID"`
+	UserID    uint      `json:"-"`
+	OldName   string    `json:"oldName"`
+	NewName   string    `json:"newName"`
+	RequestID *string   `json:"requestID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// serviceSlug reserves the slug of a Service.
+type serviceSlug struct {
+	ID        uint
+	ServiceID uint
+	UserID    uint
+	Slug      string
+	CreatedAt time.Time
+}
+
+// GetServiceBySlug returns the Service of the user which has the provided slug.
+func GetServiceBySlug(ctx context.Context, slug string, userID uint) (*Service, error) {
+	var service Service
+	err := DB.WithContext(ctx).Table(ServiceTableName).Where("user_id = ?", userID).Where("slug = ?", slug).Find(&service).Error
+	if err != nil {
+		return nil, err
+	}
+	if service.ID == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return &service, nil
+}
+
+// ListServiceRenames returns the renames of the Service with the provided ID, oldest first.
+func ListServiceRenames(ctx context.Context, svcID uint, userID uint) ([]ServiceRename, error) {
+	if _, err := GetService(ctx, svcID, userID); err != nil {
+		return nil, err
+	}
+
+	renames := make([]ServiceRename, 0)
+	db := DB.WithContext(ctx).Table(ServiceRenameTableName).Where("service_id = ?", svcID).Order("id")
+	if err := db.Find(&renames).Error; err != nil {
+		return nil, err
+	}
+	return renames, nil
+}
+
+// reserveSlug records the slug of a newly created Service. The reserved slugs of a
+// user are unique, so ErrServiceSlugTaken is returned if the slug was ever handed
+// out to another Service, and links never point to a different Service.
+func reserveSlug(tx *gorm.DB, service *Service) error {
+	s := serviceSlug{ServiceID: service.ID, UserID: uint(service.UserID), Slug: service.Slug}
+	if err := tx.Table(ServiceSlugTableName).Create(&s).Error; err != nil {
+		return serviceConstraintError(err)
+	}
+	return nil
+}
+
+// serviceConstraintError translates violations of the unique constraints of the
+// services table into the matching Error.
+func serviceConstraintError(err error) error {
+	switch {
+	case violatesConstraint(err, "services_user_id_name_key"):
+		return ErrServiceNameTaken
+	case violatesConstraint(err, "services_user_id_slug_key"),
+		violatesConstraint(err, "service_slugs_user_id_slug_key"):
+		return ErrServiceSlugTaken
+	default:
+		return err
+	}
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*))?(?:\.(0|[1-9][0-9]*))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// semver represents a version string following semantic versioning. Versions may be
+// prefixed with 'v' and omit the minor and patch parts, like 'v2'.
+type semver struct {
+	major, minor, patch int
+	prerelease          string
+}
+
+func parseSemver(version string) (semver, bool) {
+	m := semverPattern.FindStringSubmatch(version)
+	if m == nil {
+		return semver{}, false
+	}
+	return semver{
+		major:      semverPart(m[1]),
+		minor:      semverPart(m[2]),
+		patch:      semverPart(m[3]),
+		prerelease: m[4],
+	}, true
+}
+
+func semverPart(s string) int {
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// compare returns -1, 0 or 1 if s is lower than, equal to or greater than other.
+// Pre-releases are lower than the release and are compared as described by
+// comparePrerelease.
+func (s semver) compare(other semver) int {
+	for _, d := range []int{s.major - other.major, s.minor - other.minor, s.patch - other.patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	switch {
+	case s.prerelease == other.prerelease:
+		return 0
+	case s.prerelease == "":
+		return 1
+	case other.prerelease == "":
+		return -1
+	default:
+		return comparePrerelease(s.prerelease, other.prerelease)
+	}
+}
+
+// comparePrerelease compares two pre-release versions as specified by semantic
+// versioning: their dot separated identifiers are compared from left to right,
+// numerically if both are numeric and as strings otherwise, where numeric identifiers
+// are lower than others. If all identifiers are equal, the shorter version is lower.
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// isMajorBump reports whether version is a major bump of base according to semantic
+// versioning, where a minor bump counts as major below 1.0.0. ok is false if either
+// version isn't a semantic version.
+func isMajorBump(base, version string) (major bool, ok bool) {
+	b, ok := parseSemver(base)
+	if !ok {
+		return false, false
+	}
+	v, ok := parseSemver(version)
+	if !ok {
+		return false, false
+	}
+	if b.major == 0 && v.major == 0 {
+		return v.minor > b.minor, true
+	}
+	return v.major > b.major, true
+}
+
+// sortVersions sorts the Versions of a Service from oldest to newest. If all of them
+// are semantic versions they are sorted by precedence, otherwise in order of creation.
+func sortVersions(versions []Version) {
+	parsed := make([]semver, len(versions))
+	allSemver := true
+	for i, v := range versions {
+		parsed[i], allSemver = parseSemver(v.Version)
+		if !allSemver {
+			break
+		}
+	}
+	if !allSemver {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].ID < versions[j].ID
+		})
+		return
+	}
+	sort.Sort(versionsBySemver{versions: versions, parsed: parsed})
+}
+
+type versionsBySemver struct {
+	versions []Version
+	parsed   []semver
+}
+
+func (v versionsBySemver) Len() int { return len(v.versions) }
+func (v versionsBySemver) Less(i, j int) bool {
+	if c := v.parsed[i].compare(v.parsed[j]); c != 0 {
+		return c < 0
+	}
+	return v.versions[i].ID < v.versions[j].ID
+}
+func (v versionsBySemver) Swap(i, j int) {
+	v.versions[i], v.versions[j] = v.versions[j], v.versions[i]
+	v.parsed[i], v.parsed[j] = v.parsed[j], v.parsed[i]
+}
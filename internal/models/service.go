@@ -1,10 +1,15 @@
 package models
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/aryan9600/service-catalog/internal/logging"
 	"github.com/lib/pq"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -13,30 +18,173 @@ const ServiceTableName = "services"
 // Service represents a single service in the catalog.
 type Service struct {
 	Model
-	Name        string `json:"name"`
+	Name string `json:"name"`
+	// Slug is a DNS label derived from the name on creation, used to refer to the
+	// service in URLs. It never changes, even if the service is renamed.
+	Slug        string `json:"slug"`
 	Description string `json:"description"`
 	// Versions contains the different versions of this service.
 	// It helps us fetch the versions without a JOIN query.
 	Versions pq.StringArray `json:"versions" gorm:"type:varchar(50)[]"`
 	UserID   int            `json:"userID"`
+	// Owner is the team or person responsible for this service.
+	Owner string         `json:"owner"`
+	Tags  pq.StringArray `json:"tags" gorm:"type:varchar(50)[]"`
+	// Lifecycle is one of experimental, production or deprecated.
+	Lifecycle string `json:"lifecycle" gorm:"default:production"`
+	// Tier is the criticality of the service, from 1 (most critical) to 4. 0 means unclassified.
+	Tier          int          `json:"tier"`
+	RepositoryURL string       `json:"repositoryURL"`
+	Links         ServiceLinks `json:"links" gorm:"type:jsonb"`
+	Contacts      Contacts     `json:"contacts" gorm:"type:jsonb"`
+	// CustomFields must conform to the latest CustomFieldSchema.
+	CustomFields CustomFields `json:"customFields" gorm:"type:jsonb"`
+	// RowVersion is incremented every time the service is modified. It is used
+	// for optimistic concurrency control.
+	RowVersion uint `json:"-" gorm:"default:1"`
 }
 
 // ListServicesInput represnts the different input parameters that can be
 // included in the database query. The form struct tags allows for convinient
 // query parameter validation.
 type ListServicesInput struct {
-	Limit      int `form:"limit"`
-	Offset     int `form:"offset"`
-	UserID     uint
+	Limit      int    `form:"limit"`
+	Offset     int    `form:"offset"`
+	UserID     uint   `form:"-"`
 	SortKey    string `form:"sortKey"`
 	Descending bool   `form:"descending"`
 	Name       string `form:"name"`
+	Lifecycle  string `form:"lifecycle" binding:"omitempty,oneof=experimental production deprecated"`
+	Tier       *int   `form:"tier" binding:"omitempty,min=0,max=4"`
+	// RepositoryURL matches the repository URL exactly.
+	RepositoryURL string `form:"repositoryURL"`
+	// HasLink restricts the results to Services with the provided link set.
+	HasLink string `form:"hasLink" binding:"omitempty,oneof=runbook dashboard docs"`
+	// ContactType and Contact restrict the results to Services with a contact of the
+	// provided type and/or value.
+	ContactType string `form:"contactType"`
+	Contact     string `form:"contact"`
+	// CustomFields restricts the results to Services whose custom fields match all of
+	// the provided filters, each of the form 'key:value'.
+	CustomFields []string `form:"customField"`
 }
 
 // ListServices returns a list of Service objects based on the different input parameters.
-func ListServices(input ListServicesInput) ([]Service, error) {
+func ListServices(ctx context.Context, input ListServicesInput) ([]Service, error) {
 	var services []Service
-	db := DB.Table(ServiceTableName)
+	db, err := listServicesQuery(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Find(&services).Error; err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// StreamServices calls fn for each Service matching the input parameters. The rows
+// are read from the database one at a time, so that memory usage stays constant
+// regardless of the number of Services. If no sort key is provided, the Services
+// are ordered by their ID. Iteration stops at the first error returned by fn.
+func StreamServices(ctx context.Context, input ListServicesInput, fn func(Service) error) error {
+	db, err := listServicesQuery(ctx, input)
+	if err != nil {
+		return err
+	}
+	if input.SortKey == "" {
+		db = db.Order("id")
+	}
+
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var service Service
+		if err := db.ScanRows(rows, &service); err != nil {
+			return err
+		}
+		if err := fn(service); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ServiceVersionRow represents a Service along with a single one of its Versions.
+// The Version fields are empty if the Service has no Versions.
+type ServiceVersionRow struct {
+	ServiceID        uint           `json:"serviceID"`
+	Name             string         `json:"name"`
+	Description      string         `json:"description"`
+	Owner            string         `json:"owner"`
+	Tags             pq.StringArray `json:"tags" gorm:"type:varchar(50)[]"`
+	UserID           int            `json:"userID"`
+	ServiceCreatedAt time.Time      `json:"serviceCreatedAt"`
+	ServiceUpdatedAt time.Time      `json:"serviceUpdatedAt"`
+	Version          string         `json:"version"`
+	Changelog        Changelog      `json:"changelog" gorm:"type:jsonb"`
+	VersionCreatedAt *time.Time     `json:"versionCreatedAt"`
+}
+
+// StreamServiceVersions calls fn for each Version of the Services matching the input
+// parameters, in the same order as StreamServices. Services without any Versions
+// are passed to fn exactly once. Iteration stops at the first error returned by fn.
+func StreamServiceVersions(ctx context.Context, input ListServicesInput, fn func(ServiceVersionRow) error) error {
+	services, err := listServicesQuery(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	db := DB.WithContext(ctx).Table("(?) AS services", services)
+	db = db.Select([]string{
+		"services.id AS service_id",
+		"services.name",
+		"services.description",
+		"services.owner",
+		"services.tags",
+		"services.user_id",
+		"services.created_at AS service_created_at",
+		"services.updated_at AS service_updated_at",
+		"versions.version",
+		"versions.changelog",
+		"versions.created_at AS version_created_at",
+	})
+	db = db.Joins(fmt.Sprintf("LEFT JOIN %s ON services.id=versions.service_id", VersionTableName))
+	if input.SortKey != "" {
+		orderClause := "services." + input.SortKey
+		if input.Descending {
+			orderClause += " DESC"
+		}
+		db = db.Order(orderClause)
+	}
+	db = db.Order("services.id").Order("versions.id")
+
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row ServiceVersionRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// listServicesQuery returns a query for the services table with the filters,
+// pagination and ordering of the input parameters applied.
+func listServicesQuery(ctx context.Context, input ListServicesInput) (*gorm.DB, error) {
+	db := DB.WithContext(ctx).Table(ServiceTableName)
 
 	if input.UserID != 0 {
 		db = db.Where("user_id = ?", input.UserID)
@@ -45,13 +193,46 @@ func ListServices(input ListServicesInput) ([]Service, error) {
 		match := "%" + input.Name + "%"
 		db = db.Where("name LIKE ? ", match)
 	}
+	if input.Lifecycle != "" {
+		db = db.Where("lifecycle = ?", input.Lifecycle)
+	}
+	if input.Tier != nil {
+		db = db.Where("tier = ?", *input.Tier)
+	}
+	if input.RepositoryURL != "" {
+		db = db.Where("repository_url = ?", input.RepositoryURL)
+	}
+	if input.HasLink != "" {
+		db = db.Where("COALESCE(links ->> ?, '') <> ''", input.HasLink)
+	}
+	if input.ContactType != "" || input.Contact != "" {
+		contact := map[string]string{}
+		if input.ContactType != "" {
+			contact["type"] = input.ContactType
+		}
+		if input.Contact != "" {
+			contact["value"] = input.Contact
+		}
+		filter, err := json.Marshal([]map[string]string{contact})
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("contacts @> ?::jsonb", string(filter))
+	}
+	for _, f := range input.CustomFields {
+		filter, err := parseCustomFieldFilter(f)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("custom_fields @> ?::jsonb", filter)
+	}
 	if input.Limit != 0 {
 		db = db.Limit(input.Limit).Offset(input.Offset)
 	}
 
 	if input.SortKey != "" {
 		if ok := isValidSortKey(input.SortKey); !ok {
-			return nil, fmt.Errorf("invalid sort key: %s", input.SortKey)
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSortKey, input.SortKey)
 		}
 		orderClause := input.SortKey
 		if input.Descending {
@@ -59,11 +240,7 @@ func ListServices(input ListServicesInput) ([]Service, error) {
 		}
 		db = db.Order(orderClause)
 	}
-
-	if err := db.Find(&services).Error; err != nil {
-		return nil, err
-	}
-	return services, nil
+	return db, nil
 }
 
 // GetServiceWithVersionsTxOutput represents the various columns returned by the
@@ -73,12 +250,23 @@ type GetServiceWithVersionsTxOutput struct {
 	ServiceCreatedAt time.Time
 	ServiceUpdatedAt time.Time
 	Name             string
+	Slug             string
 	Description      string
+	Owner            string
+	Tags             pq.StringArray `gorm:"type:varchar(50)[]"`
+	Lifecycle        string
+	Tier             int
+	RepositoryURL    string
+	Links            ServiceLinks `gorm:"type:jsonb"`
+	Contacts         Contacts     `gorm:"type:jsonb"`
+	CustomFields     CustomFields `gorm:"type:jsonb"`
+	RowVersion       uint
 	VersionID        uint
 	VersionCreatedAt time.Time
 	VersionUpdatedAt time.Time
 	Version          string
-	Changelog        string
+	Changelog        Changelog       `gorm:"type:jsonb"`
+	Release          ReleaseMetadata `gorm:"embedded;embeddedPrefix:release_"`
 }
 
 // getServiceWithVersionsTxFields returns the SQL compatible stringfied names
@@ -92,17 +280,31 @@ func getServiceWithVersionsTxFields() []string {
 		"versions.created_at as version_created_at",
 		"versions.updated_at as version_updated_at",
 		"services.name",
+		"services.slug",
 		"services.description",
+		"services.owner",
+		"services.tags",
+		"services.lifecycle",
+		"services.tier",
+		"services.repository_url",
+		"services.links",
+		"services.contacts",
+		"services.custom_fields",
+		"services.row_version",
 		"versions.version",
 		"versions.changelog",
+		"versions.release_commit",
+		"versions.release_build_time",
+		"versions.release_image_digest",
+		"versions.release_artifacts",
 	}
 }
 
 // GetServiceWithVersions returns the requested Service for the provided ID along
 // of the Version objects belonging to this Service.
-func GetServiceWithVersions(svcID uint, userID uint) ([]GetServiceWithVersionsTxOutput, error) {
+func GetServiceWithVersions(ctx context.Context, svcID uint, userID uint) ([]GetServiceWithVersionsTxOutput, error) {
 	output := make([]GetServiceWithVersionsTxOutput, 0)
-	db := DB.Table(ServiceTableName)
+	db := DB.WithContext(ctx).Table(ServiceTableName)
 
 	fields := getServiceWithVersionsTxFields()
 	db = db.Select(fields)
@@ -124,8 +326,12 @@ func GetServiceWithVersions(svcID uint, userID uint) ([]GetServiceWithVersionsTx
 }
 
 // GetService returns the Service for the provided ID.
-func GetService(svcID uint, userID uint) (*Service, error) {
-	db := DB.Table(ServiceTableName)
+func GetService(ctx context.Context, svcID uint, userID uint) (*Service, error) {
+	return getService(DB.WithContext(ctx), svcID, userID)
+}
+
+func getService(tx *gorm.DB, svcID uint, userID uint) (*Service, error) {
+	db := tx.Table(ServiceTableName)
 	db = db.Where("id = ?", svcID)
 	if userID != 0 {
 		db = db.Where("user_id = ?", userID)
@@ -145,53 +351,255 @@ func GetService(svcID uint, userID uint) (*Service, error) {
 
 // CreateServiceInput represents the input required to create a Service.
 type CreateServiceInput struct {
-	Name        string `json:"name" binding:"required,max=50"`
-	Description string `json:"description"`
-	UserID      uint
+	Name string `json:"name" binding:"required"`
+	// Slug is derived from the name if it's empty.
+	Slug        string   `json:"slug"`
+	Description string   `json:"description"`
+	Owner       string   `json:"owner" binding:"max=255"`
+	Tags        []string `json:"tags" binding:"dive,max=50"`
+	// Lifecycle defaults to production.
+	Lifecycle     string       `json:"lifecycle" binding:"omitempty,oneof=experimental production deprecated"`
+	Tier          int          `json:"tier" binding:"min=0,max=4"`
+	RepositoryURL string       `json:"repositoryURL" binding:"omitempty,url,max=2048"`
+	Links         ServiceLinks `json:"links"`
+	Contacts      []Contact    `json:"contacts" binding:"max=20,dive"`
+	CustomFields  CustomFields `json:"customFields"`
+	UserID        uint         `json:"-"`
 }
 
 // CreateService creates a new Service.
-func CreateService(input CreateServiceInput) (*Service, error) {
-	db := DB.Table(ServiceTableName)
-	service := Service{
-		Name:        input.Name,
-		Description: input.Description,
-		UserID:      int(input.UserID),
+func CreateService(ctx context.Context, input CreateServiceInput) (*Service, error) {
+	return createService(DB.WithContext(ctx), input)
+}
+
+func createService(tx *gorm.DB, input CreateServiceInput) (*Service, error) {
+	if err := validateServiceName(input.Name); err != nil {
+		return nil, err
 	}
-	if err := db.Create(&service).Error; err != nil {
+	slug := input.Slug
+	if slug == "" {
+		slug = Slugify(input.Name)
+	} else if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
 
+	service := Service{
+		Name:          input.Name,
+		Slug:          slug,
+		Description:   input.Description,
+		UserID:        int(input.UserID),
+		Owner:         input.Owner,
+		Tags:          input.Tags,
+		Lifecycle:     input.Lifecycle,
+		Tier:          input.Tier,
+		RepositoryURL: input.RepositoryURL,
+		Links:         input.Links,
+		Contacts:      input.Contacts,
+		CustomFields:  input.CustomFields,
+	}
+	// Nested transactions use savepoints, so this works inside transactions as well.
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		if err := validateCustomFields(tx, input.CustomFields); err != nil {
+			return err
+		}
+		if err := tx.Table(ServiceTableName).Create(&service).Error; err != nil {
+			return serviceConstraintError(err)
+		}
+		return reserveSlug(tx, &service)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &service, nil
 }
 
-// CreateServiceInput represents the input required to create a Service.
+// UpdateServiceInput represents the input required to update a Service.
+// Nil fields are left untouched, while all other fields are written, even if
+// they are empty. This allows clearing a field by providing an empty value.
 type UpdateServiceInput struct {
-	Name        string `json:"name" binding:"max=50"`
-	Description string `json:"description"`
+	Name        *string  `json:"name" binding:"omitempty,min=1"`
+	Description *string  `json:"description"`
+	Owner       *string  `json:"owner" binding:"omitempty,max=255"`
+	Tags        []string `json:"tags" binding:"dive,max=50"`
+	Lifecycle   *string  `json:"lifecycle" binding:"omitempty,oneof=experimental production deprecated"`
+	Tier        *int     `json:"tier" binding:"omitempty,min=0,max=4"`
+	// RepositoryURL can be cleared with an empty string.
+	RepositoryURL *string `json:"repositoryURL" binding:"omitempty,max=2048,eq=|url"`
+	// Links replaces all links of the Service.
+	Links    *ServiceLinks `json:"links"`
+	Contacts []Contact     `json:"contacts" binding:"max=20,dive"`
+	// CustomFields replaces all custom fields of the Service.
+	CustomFields CustomFields `json:"customFields"`
+	// ExpectedRowVersions, if not empty, restricts the update to a Service whose
+	// current row version is one of these.
+	ExpectedRowVersions []uint `json:"-"`
+}
+
+// IsEmpty returns true if the input doesn't update any field.
+func (u UpdateServiceInput) IsEmpty() bool {
+	return u.Name == nil && u.Description == nil && u.Owner == nil && u.Tags == nil &&
+		u.Lifecycle == nil && u.Tier == nil && u.RepositoryURL == nil && u.Links == nil && u.Contacts == nil &&
+		u.CustomFields == nil
+}
+
+// UpdateService updates the Service with the provided ID according to the input.
+// If the input contains expected row versions which don't match the Service's
+// current one, ErrRowVersionMismatch is returned. Renaming the Service records the
+// rename; its slug stays the same.
+func UpdateService(ctx context.Context, input UpdateServiceInput, id uint, userID uint) (*Service, error) {
+	return updateService(DB.WithContext(ctx), input, id, userID)
+}
+
+func updateService(db *gorm.DB, input UpdateServiceInput, id uint, userID uint) (*Service, error) {
+	if input.Name == nil && input.CustomFields == nil {
+		return updateServiceFields(db, input, id, userID)
+	}
+	if input.Name != nil {
+		if err := validateServiceName(*input.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	var updated *Service
+	var renamed *ServiceRename
+	// Custom fields must be validated in the same transaction as they are written, see
+	// validateCustomFields. Nested transactions use savepoints, so this works inside
+	// transactions as well.
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if input.Name == nil {
+			var err error
+			updated, err = updateServiceFields(tx, input, id, userID)
+			return err
+		}
+		current, err := getService(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id, userID)
+		if err != nil {
+			return err
+		}
+		updated, err = updateServiceFields(tx, input, id, userID)
+		if err != nil || current.Name == *input.Name {
+			return err
+		}
+
+		rename := &ServiceRename{
+			ServiceID: current.ID,
+			UserID:    uint(current.UserID),
+			OldName:   current.Name,
+			NewName:   *input.Name,
+			RequestID: requestID(tx.Statement.Context),
+		}
+		renamed = rename
+		return tx.Table(ServiceRenameTableName).Create(rename).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if renamed != nil {
+		logging.FromContext(db.Statement.Context).Info().
+			Uint("service_id", renamed.ServiceID).
+			Str("old_name", renamed.OldName).
+			Str("new_name", renamed.NewName).
+			Msg("service renamed")
+	}
+	return updated, nil
 }
 
-func UpdateService(input UpdateServiceInput, id uint, userID uint) (*Service, error) {
+// updateServiceFields does the work of updateService. If the input contains custom
+// fields, it must be called inside a transaction, see validateCustomFields.
+func updateServiceFields(tx *gorm.DB, input UpdateServiceInput, id uint, userID uint) (*Service, error) {
 	var updated Service
-	db := DB.Model(&updated)
+	db := tx.Model(&updated)
 	db = db.Where("id = ?", id)
 	if userID != 0 {
 		db = db.Where("user_id = ?", userID)
 	}
+	if len(input.ExpectedRowVersions) > 0 {
+		db = db.Where("row_version IN ?", input.ExpectedRowVersions)
+	}
 
-	svc := Service{Name: input.Name, Description: input.Description}
-	if err := db.Clauses(clause.Returning{}).Updates(svc).Error; err != nil {
-		return nil, err
+	updates := map[string]interface{}{
+		"row_version": gorm.Expr("row_version + 1"),
+	}
+	if input.Name != nil {
+		updates["name"] = *input.Name
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+	if input.Owner != nil {
+		updates["owner"] = *input.Owner
+	}
+	if input.Tags != nil {
+		updates["tags"] = pq.StringArray(input.Tags)
+	}
+	if input.Lifecycle != nil {
+		updates["lifecycle"] = *input.Lifecycle
+	}
+	if input.Tier != nil {
+		updates["tier"] = *input.Tier
+	}
+	if input.RepositoryURL != nil {
+		updates["repository_url"] = *input.RepositoryURL
+	}
+	if input.Links != nil {
+		updates["links"] = *input.Links
+	}
+	if input.Contacts != nil {
+		updates["contacts"] = Contacts(input.Contacts)
+	}
+	if input.CustomFields != nil {
+		if err := validateCustomFields(tx, input.CustomFields); err != nil {
+			return nil, err
+		}
+		updates["custom_fields"] = input.CustomFields
+	}
+	if err := db.Clauses(clause.Returning{}).Updates(updates).Error; err != nil {
+		return nil, serviceConstraintError(err)
 	}
 	if updated.ID == 0 {
+		if len(input.ExpectedRowVersions) > 0 {
+			// Figure out whether the Service doesn't exist or was modified concurrently.
+			if _, err := getService(tx, id, userID); err != nil {
+				return nil, err
+			}
+			return nil, ErrRowVersionMismatch
+		}
 		return nil, ErrRecordNotFound
 	}
 	return &updated, nil
 }
 
+// PatchService locks the Service with the provided ID, passes it to patch and
+// updates it according to the input returned by patch, all in a single transaction.
+// If expectedRowVersions is not empty and doesn't contain the Service's current row
+// version, ErrRowVersionMismatch is returned. Errors returned by patch are returned as is.
+func PatchService(ctx context.Context, id uint, userID uint, expectedRowVersions []uint, patch func(Service) (UpdateServiceInput, error)) (*Service, error) {
+	var updated *Service
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := getService(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id, userID)
+		if err != nil {
+			return err
+		}
+		if len(expectedRowVersions) > 0 && !slices.Contains(expectedRowVersions, current.RowVersion) {
+			return ErrRowVersionMismatch
+		}
+
+		input, err := patch(*current)
+		if err != nil {
+			return err
+		}
+		input.ExpectedRowVersions = nil
+		updated, err = updateService(tx, input, id, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
 func isValidSortKey(sortKey string) bool {
 	switch sortKey {
-	case "name", "created_at", "updated_at":
+	case "name", "created_at", "updated_at", "tier":
 		return true
 	default:
 		return false
@@ -0,0 +1,285 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"github.com/aryan9600/service-catalog/internal/spec"
+	"github.com/aryan9600/service-catalog/internal/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	VersionSpecTableName = "version_specs"
+	specBlobTableName    = "spec_blobs"
+)
+
+// VersionSpec represents the API specification attached to a Version. The content
+// itself is kept in the configured blob store under StorageKey.
+type VersionSpec struct {
+	Model
+	VersionID   uint   `json:"versionID"`
+	Format      string `json:"format"`
+	Encoding    string `json:"encoding"`
+	ContentType string `json:"contentType"`
+	Size        int    `json:"size"`
+	SHA256      string `json:"sha256" gorm:"column:sha256"`
+	StorageKey  string `json:"-"`
+	// Compatibility is only set right after an OpenAPI spec is attached.
+	Compatibility *CompatibilityReport `json:"compatibility,omitempty" gorm:"-"`
+}
+
+// PutVersionSpecInput represents a validated API specification to be attached to a Version.
+type PutVersionSpecInput struct {
+	Format      string
+	Encoding    string
+	ContentType string
+	Content     []byte
+}
+
+var specStore storage.BlobStore
+
+// ParseSpec detects the format of the API specification and validates it, see
+// spec.Parse. format may be empty.
+func ParseSpec(content []byte, format string) (*PutVersionSpecInput, error) {
+	parsed, err := spec.Parse(content, format)
+	if err != nil {
+		return nil, &Error{Kind: ErrorKindUnprocessable, Code: "invalid_spec", Message: err.Error()}
+	}
+	return &PutVersionSpecInput{
+		Format:      parsed.Format,
+		Encoding:    parsed.Encoding,
+		ContentType: parsed.ContentType(),
+		Content:     parsed.Content,
+	}, nil
+}
+
+// SetSpecStore sets the blob store used for the content of API specifications.
+func SetSpecStore(store storage.BlobStore) {
+	specStore = store
+}
+
+// SetSpecStorageConfig sets up the blob store used for API specifications: either
+// the filesystem, storing specs under cfg.StorageDir, or the database.
+func SetSpecStorageConfig(cfg config.Specs) error {
+	switch cfg.Storage {
+	case "filesystem":
+		store, err := storage.NewFilesystemStore(cfg.StorageDir)
+		if err != nil {
+			return err
+		}
+		SetSpecStore(store)
+	case "database":
+		SetSpecStore(databaseBlobStore{})
+	default:
+		return fmt.Errorf("invalid spec storage: %s; must be one of: filesystem, database", cfg.Storage)
+	}
+	return nil
+}
+
+// PutVersionSpec attaches the API specification to the provided Version of the
+// Service, replacing any existing one. OpenAPI specs are checked for breaking
+// changes against the spec of the previous Version, see checkCompatibility.
+func PutVersionSpec(ctx context.Context, svcID uint, userID uint, version string, input PutVersionSpecInput) (*VersionSpec, error) {
+	var vs *VersionSpec
+	var oldKey string
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		v, err := getVersion(tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: VersionTableName}}), svcID, userID, version)
+		if err != nil {
+			return err
+		}
+		vs, oldKey, err = putVersionSpec(tx, v, input)
+		return err
+	})
+	if err != nil {
+		// The spec may have been stored before the transaction failed to commit.
+		if vs != nil {
+			deleteOldSpecBlob(vs.StorageKey, oldKey)
+		}
+		return nil, err
+	}
+	deleteOldSpecBlob(oldKey, vs.StorageKey)
+	return vs, nil
+}
+
+// putVersionSpec does the work of PutVersionSpec. It must be called inside a transaction
+// in which the Version is locked. The storage key of the replaced spec is returned, so
+// that its blob can be deleted once the transaction is committed.
+func putVersionSpec(tx *gorm.DB, v *Version, input PutVersionSpecInput) (*VersionSpec, string, error) {
+	if specStore == nil {
+		return nil, "", errors.New("spec storage is not configured")
+	}
+
+	sum := sha256.Sum256(input.Content)
+	vs := &VersionSpec{
+		VersionID:   v.ID,
+		Format:      input.Format,
+		Encoding:    input.Encoding,
+		ContentType: input.ContentType,
+		Size:        len(input.Content),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+	vs.StorageKey = fmt.Sprintf("specs/%d/%d/%s", v.ServiceID, v.ID, vs.SHA256)
+
+	var existing VersionSpec
+	if err := tx.Table(VersionSpecTableName).Where("version_id = ?", v.ID).Find(&existing).Error; err != nil {
+		return nil, "", err
+	}
+	report, err := checkCompatibility(tx, v, input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Content addressed keys make sure that the blob of the spec currently in the
+	// database is never overwritten, even if the transaction fails.
+	if err := specStore.Put(tx.Statement.Context, vs.StorageKey, input.Content); err != nil {
+		return nil, "", fmt.Errorf("unable to store spec: %w", err)
+	}
+	if existing.ID != 0 {
+		vs.ID = existing.ID
+		vs.CreatedAt = existing.CreatedAt
+	}
+	err = tx.Table(VersionSpecTableName).Save(vs).Error
+	if err == nil {
+		err = saveCompatibilityReport(tx, v.ID, report)
+	}
+	if err != nil {
+		deleteOldSpecBlob(vs.StorageKey, existing.StorageKey)
+		return nil, "", err
+	}
+	vs.Compatibility = report
+	return vs, existing.StorageKey, nil
+}
+
+// deleteOldSpecBlob deletes the blob stored under oldKey, unless it's the same as
+// currentKey. Errors are ignored, since the blob is no longer referenced.
+func deleteOldSpecBlob(oldKey, currentKey string) {
+	if oldKey != "" && oldKey != currentKey {
+		_ = specStore.Delete(context.Background(), oldKey)
+	}
+}
+
+// discardSpecBlob deletes the blob of the spec attached to the Version, after the
+// transaction which created the Version was rolled back. Since specs of new Versions
+// are stored under keys of their own, the blob isn't referenced by anything else.
+func discardSpecBlob(v *Version) {
+	if v != nil && v.Spec != nil {
+		_ = specStore.Delete(context.Background(), v.Spec.StorageKey)
+	}
+}
+
+// GetVersionSpec returns the API specification attached to the provided Version
+// of the Service, along with a reader for its content which must be closed by
+// the caller. ErrRecordNotFound is returned if the Version has no specification.
+func GetVersionSpec(ctx context.Context, svcID uint, userID uint, version string) (*VersionSpec, io.ReadCloser, error) {
+	vs, err := getVersionSpec(DB.WithContext(ctx), svcID, userID, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if specStore == nil {
+		return nil, nil, errors.New("spec storage is not configured")
+	}
+	content, err := specStore.Get(ctx, vs.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read spec %s: %w", vs.StorageKey, err)
+	}
+	return vs, content, nil
+}
+
+func getVersionSpec(tx *gorm.DB, svcID uint, userID uint, version string) (*VersionSpec, error) {
+	v, err := getVersion(tx, svcID, userID, version)
+	if err != nil {
+		return nil, err
+	}
+	return getVersionSpecByVersionID(tx, v.ID)
+}
+
+func getVersionSpecByVersionID(tx *gorm.DB, versionID uint) (*VersionSpec, error) {
+	var vs VersionSpec
+	if err := tx.Table(VersionSpecTableName).Where("version_id = ?", versionID).Find(&vs).Error; err != nil {
+		return nil, err
+	}
+	if vs.ID == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return &vs, nil
+}
+
+// DeleteVersionSpec removes the API specification from the provided Version of the Service.
+func DeleteVersionSpec(ctx context.Context, svcID uint, userID uint, version string) error {
+	var vs *VersionSpec
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		vs, err = getVersionSpec(tx, svcID, userID, version)
+		if err != nil {
+			return err
+		}
+		if err := tx.Table(VersionSpecTableName).Delete(vs).Error; err != nil {
+			return err
+		}
+		return saveCompatibilityReport(tx, vs.VersionID, nil)
+	})
+	if err != nil {
+		return err
+	}
+	if specStore != nil {
+		_ = specStore.Delete(context.Background(), vs.StorageKey)
+	}
+	return nil
+}
+
+// getVersion returns the Version of the Service with the provided version string.
+func getVersion(tx *gorm.DB, svcID uint, userID uint, version string) (*Version, error) {
+	var v Version
+	db := tx.Table(VersionTableName).Select("versions.*")
+	db = db.Joins(fmt.Sprintf("JOIN %s ON services.id=versions.service_id", ServiceTableName))
+	db = db.Where("versions.service_id = ?", svcID).Where("versions.version = ?", version)
+	if userID != 0 {
+		db = db.Where("services.user_id = ?", userID)
+	}
+	if err := db.Find(&v).Error; err != nil {
+		return nil, err
+	}
+	if v.ID == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return &v, nil
+}
+
+// databaseBlobStore is a storage.BlobStore which stores blobs in the spec_blobs table.
+type databaseBlobStore struct{}
+
+type specBlob struct {
+	Key     string
+	Content []byte
+}
+
+func (databaseBlobStore) Put(ctx context.Context, key string, content []byte) error {
+	blob := specBlob{Key: key, Content: content}
+	return DB.WithContext(ctx).Table(specBlobTableName).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"content"}),
+	}).Create(&blob).Error
+}
+
+func (databaseBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	var blob specBlob
+	if err := DB.WithContext(ctx).Table(specBlobTableName).Where("key = ?", key).Find(&blob).Error; err != nil {
+		return nil, err
+	}
+	if blob.Key == "" {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(blob.Content)), nil
+}
+
+func (databaseBlobStore) Delete(ctx context.Context, key string) error {
+	return DB.WithContext(ctx).Table(specBlobTableName).Where("key = ?", key).Delete(&specBlob{}).Error
+}
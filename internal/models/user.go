@@ -1,8 +1,7 @@
 package models
 
 import (
-	"strings"
-
+	"context"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -13,11 +12,13 @@ type User struct {
 	Model
 	Username string `json:"username"`
 	Password string `json:"-"`
+	// IsAdmin allows the user to manage catalog wide settings, like the custom field schema.
+	IsAdmin bool `json:"isAdmin"`
 }
 
 // GetUserByUsername returns the User for the provided username.
-func GetUserByUsername(username string) (*User, error) {
-	db := DB.Table(UserTableName)
+func GetUserByUsername(ctx context.Context, username string) (*User, error) {
+	db := DB.WithContext(ctx).Table(UserTableName)
 	db.Where("username = ? ", username)
 
 	var user User
@@ -31,9 +32,9 @@ func GetUserByUsername(username string) (*User, error) {
 }
 
 // GetUserByID returns the User for the provided ID.
-func GetUserByID(id uint) (*User, error) {
+func GetUserByID(ctx context.Context, id uint) (*User, error) {
 	var user User
-	db := DB.Table(UserTableName)
+	db := DB.WithContext(ctx).Table(UserTableName)
 	if err := db.Where("id = ?", id).Find(&user).Error; err != nil {
 		return nil, err
 	}
@@ -44,7 +45,7 @@ func GetUserByID(id uint) (*User, error) {
 }
 
 // CreateUser creates a user with the provided username and password.
-func CreateUser(username, password string) (*User, error) {
+func CreateUser(ctx context.Context, username, password string) (*User, error) {
 	hashedPassword, err := GetPasswordHash(password)
 	if err != nil {
 		return nil, err
@@ -53,9 +54,9 @@ func CreateUser(username, password string) (*User, error) {
 		Username: username,
 		Password: hashedPassword,
 	}
-	db := DB.Table(UserTableName)
+	db := DB.WithContext(ctx).Table(UserTableName)
 	if err := db.Create(user).Error; err != nil {
-		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		if isUniqueConstraintViolation(err) {
 			return nil, ErrUniqueConstraintViolation
 		}
 		return nil, err
@@ -1,8 +1,7 @@
 package models
 
 import (
-	"strings"
-
+	"context"
 	"gorm.io/gorm"
 )
 
@@ -11,50 +10,92 @@ const VersionTableName = "versions"
 // Version represents a version of a Service.
 type Version struct {
 	Model
-	Version   string `json:"version"`
-	ServiceID int    `json:"serviceID"`
-	Changelog string `json:"changelog"`
+	Version   string          `json:"version"`
+	ServiceID int             `json:"serviceID"`
+	Changelog Changelog       `json:"changelog" gorm:"type:jsonb"`
+	Release   ReleaseMetadata `json:"release" gorm:"embedded;embeddedPrefix:release_"`
+	// Spec is only set right after the Version is created with an API specification.
+	Spec *VersionSpec `json:"spec,omitempty" gorm:"-"`
 }
 
 // CreateVersionInput represents the input required to create Version object.
 type CreateVersionInput struct {
-	Version   string `json:"version" binding:"required,max=50"`
-	ServiceID int
-	Changelog string `json:"changelog"`
-	UserID    uint
+	Version   string          `json:"version" binding:"required,max=50"`
+	ServiceID int             `json:"-"`
+	Changelog Changelog       `json:"changelog" binding:"max=50,dive"`
+	Release   ReleaseMetadata `json:"release"`
+	// Spec is an optional API specification to attach to the Version, see PutVersionSpec.
+	Spec       string `json:"spec"`
+	SpecFormat string `json:"specFormat" binding:"omitempty,oneof=openapi asyncapi protobuf"`
+	UserID     uint   `json:"-"`
 }
 
 // CreateVersion fetches the Service with the provided id, and if it exists, it creates
 // a new Version according to the input and then update the related Service with the new
 // version string.
-func CreateVersion(input CreateVersionInput) (*Version, error) {
+func CreateVersion(ctx context.Context, input CreateVersionInput) (*Version, error) {
+	var version *Version
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		version, err = createVersion(tx, input)
+		return err
+	})
+	if err != nil {
+		discardSpecBlob(version)
+		return nil, err
+	}
+	return version, nil
+}
+
+// createVersion does the work of CreateVersion. It must be called inside a transaction.
+func createVersion(tx *gorm.DB, input CreateVersionInput) (*Version, error) {
+	var specInput *PutVersionSpecInput
+	if input.Spec != "" {
+		var err error
+		specInput, err = ParseSpec([]byte(input.Spec), input.SpecFormat)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := normalizeRelease(&input.Release); err != nil {
+		return nil, err
+	}
 	version := &Version{
 		Version:   input.Version,
 		ServiceID: input.ServiceID,
 		Changelog: input.Changelog,
+		Release:   input.Release,
 	}
-	err := DB.Transaction(func(tx *gorm.DB) error {
-		var service Service
-		if err := tx.Model(&service).Where("id = ?", input.ServiceID).Where("user_id = ?", input.UserID).Find(&service).Error; err != nil {
-			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-				return ErrUniqueConstraintViolation
-			}
-			return err
-		}
-		if service.ID == 0 {
-			return ErrRecordNotFound
-		}
-		if err := tx.Model(version).Create(version).Error; err != nil {
-			return err
-		}
-		service.Versions = append(service.Versions, version.Version)
-		if err := tx.Model(&service).Save(&service).Error; err != nil {
-			return err
+	var service Service
+	if err := tx.Model(&service).Where("id = ?", input.ServiceID).Where("user_id = ?", input.UserID).Find(&service).Error; err != nil {
+		return nil, err
+	}
+	if service.ID == 0 {
+		return nil, ErrRecordNotFound
+	}
+	if err := tx.Model(version).Create(version).Error; err != nil {
+		if isUniqueConstraintViolation(err) {
+			return nil, ErrUniqueConstraintViolation
 		}
-		return nil
-	})
+		return nil, err
+	}
+	// Only touch the columns which change, so that concurrent updates of the
+	// Service's other fields aren't overwritten with the values read above.
+	err := tx.Model(&service).Updates(map[string]interface{}{
+		"versions":    gorm.Expr("array_append(versions, ?)", version.Version),
+		"row_version": gorm.Expr("row_version + 1"),
+	}).Error
 	if err != nil {
 		return nil, err
 	}
+	if specInput != nil {
+		// If the transaction is rolled back later on, the caller must discard the blob
+		// of the spec, see discardSpecBlob.
+		vs, _, err := putVersionSpec(tx, version, *specInput)
+		if err != nil {
+			return nil, err
+		}
+		version.Spec = vs
+	}
 	return version, nil
 }
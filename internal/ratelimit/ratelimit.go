@@ -0,0 +1,138 @@
+// Package ratelimit limits the rate of requests with token buckets.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Policy is the rate limit of a group of routes. Every key gets a bucket of Burst
+// tokens, refilled at Requests tokens per Period, and every request takes a token.
+type Policy struct {
+	Name     string
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// rate returns the number of tokens added to a bucket per second.
+func (p Policy) rate() float64 {
+	return float64(p.Requests) / p.Period.Seconds()
+}
+
+// Result is the outcome of a request taking a token.
+type Result struct {
+	Allowed bool
+	// Limit is the size of the bucket.
+	Limit int
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// Reset is how long it takes for the bucket to be full again.
+	Reset time.Duration
+	// RetryAfter is, if the request isn't allowed, how long it takes for a token
+	// to be available.
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether requests are allowed. Implementations must be safe for
+// concurrent use.
+type Limiter interface {
+	// Allow takes a token from the bucket of the key for the policy, if there is one.
+	Allow(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// Bucket is the state of a token bucket.
+type Bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// NewBucket returns a full bucket for the policy.
+func NewBucket(policy Policy, now time.Time) Bucket {
+	return Bucket{Tokens: float64(policy.Burst), Updated: now}
+}
+
+// Take refills the bucket with the tokens added since it was last updated and takes
+// a token, if there is one.
+func (b *Bucket) Take(policy Policy, now time.Time) Result {
+	rate := policy.rate()
+	if elapsed := now.Sub(b.Updated).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(float64(policy.Burst), b.Tokens+elapsed*rate)
+		b.Updated = now
+	}
+
+	result := Result{Limit: policy.Burst}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.Tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(b.Tokens))
+	result.Reset = seconds((float64(policy.Burst) - b.Tokens) / rate)
+	return result
+}
+
+// FullAt returns when the bucket will be full again, after which it can be forgotten.
+func (b *Bucket) FullAt(policy Policy) time.Time {
+	return b.Updated.Add(seconds((float64(policy.Burst) - b.Tokens) / policy.rate()))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// sweepInterval is how often MemoryLimiter forgets the buckets which are full.
+const sweepInterval = time.Minute
+
+// MemoryLimiter is a Limiter keeping buckets in memory. Every replica of the server
+// limits requests on its own.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryBucket struct {
+	Bucket
+	policy Policy
+}
+
+// NewMemoryLimiter returns an empty MemoryLimiter.
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow implements Limiter.
+func (m *MemoryLimiter) Allow(_ context.Context, key string, policy Policy) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+	key = policy.Name + ":" + key
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &memoryBucket{Bucket: NewBucket(policy, now), policy: policy}
+		m.buckets[key] = b
+	}
+	return b.Take(policy, now), nil
+}
+
+// sweep forgets the buckets which are full, since they are the same as new ones.
+func (m *MemoryLimiter) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if !b.FullAt(b.policy).After(now) {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,316 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Kinds of changes between two OpenAPI documents.
+const (
+	ChangeOperationRemoved          = "operation_removed"
+	ChangeOperationAdded            = "operation_added"
+	ChangeParameterRemoved          = "parameter_removed"
+	ChangeRequiredParameterAdded    = "required_parameter_added"
+	ChangeOptionalParameterAdded    = "optional_parameter_added"
+	ChangeParameterBecameRequired   = "parameter_became_required"
+	ChangeRequestBodyBecameRequired = "request_body_became_required"
+	ChangeRequiredPropertyAdded     = "required_property_added"
+	ChangePropertyBecameRequired    = "property_became_required"
+	ChangePropertyRemoved           = "property_removed"
+	ChangeTypeChanged               = "type_changed"
+	ChangeEnumValueRemoved          = "enum_value_removed"
+	ChangeResponseRemoved           = "response_removed"
+	ChangeMediaTypeRemoved          = "media_type_removed"
+)
+
+// Change represents a single difference between two OpenAPI documents.
+type Change struct {
+	Kind     string `json:"kind"`
+	Breaking bool   `json:"breaking"`
+	// Operation is the affected operation, e.g. 'GET /pets/{id}'.
+	Operation string `json:"operation"`
+	// Location points to the changed element within the operation, e.g.
+	// 'query.limit' or 'response.200.application/json.name'.
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message"`
+}
+
+// schemaDirection tells whether a schema describes data sent by clients or
+// returned to them, which decides whether a change of the schema is breaking.
+type schemaDirection int
+
+const (
+	directionRequest schemaDirection = iota
+	directionResponse
+)
+
+// maxSchemaDepth limits how deep nested schemas are compared.
+const maxSchemaDepth = 16
+
+var pathParam = regexp.MustCompile(`\{[^}]*\}`)
+
+// Diff compares two OpenAPI documents and classifies the changes from base to
+// revision as breaking or not, from the point of view of existing clients. Removed
+// operations, new required parameters or properties in requests, removed properties
+// in responses and type changes are breaking. Changes are ordered by operation.
+func Diff(base, revision *openapi3.T) []Change {
+	d := &differ{}
+	baseOps := operations(base)
+	revisionOps := operations(revision)
+
+	for _, key := range sortedKeys(baseOps, revisionOps) {
+		oldOp, newOp := baseOps[key], revisionOps[key]
+		switch {
+		case newOp == nil:
+			d.add(ChangeOperationRemoved, true, oldOp.name, "", "operation was removed")
+		case oldOp == nil:
+			d.add(ChangeOperationAdded, false, newOp.name, "", "operation was added")
+		default:
+			d.diffOperation(newOp.name, oldOp, newOp)
+		}
+	}
+	return d.changes
+}
+
+// Breaking reports whether any of the changes is breaking.
+func Breaking(changes []Change) bool {
+	for _, change := range changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+type operation struct {
+	name       string
+	op         *openapi3.Operation
+	parameters map[string]*openapi3.Parameter
+}
+
+// operations returns the operations of the document keyed by method and path,
+// with path parameter names removed so that renaming a path parameter doesn't
+// count as removing the operation.
+func operations(t *openapi3.T) map[string]*operation {
+	ops := make(map[string]*operation)
+	if t == nil || t.Paths == nil {
+		return ops
+	}
+	for path, item := range t.Paths.Map() {
+		if item == nil {
+			continue
+		}
+		for method, op := range item.Operations() {
+			o := &operation{
+				name:       method + " " + path,
+				op:         op,
+				parameters: make(map[string]*openapi3.Parameter),
+			}
+			// Operation parameters override those of the path item.
+			for _, params := range []openapi3.Parameters{item.Parameters, op.Parameters} {
+				for _, ref := range params {
+					if ref == nil || ref.Value == nil {
+						continue
+					}
+					p := ref.Value
+					if p.In == openapi3.ParameterInPath {
+						continue
+					}
+					o.parameters[p.In+"."+p.Name] = p
+				}
+			}
+			ops[method+" "+pathParam.ReplaceAllString(path, "{}")] = o
+		}
+	}
+	return ops
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(kind string, breaking bool, operation, location, message string) {
+	d.changes = append(d.changes, Change{
+		Kind:      kind,
+		Breaking:  breaking,
+		Operation: operation,
+		Location:  location,
+		Message:   message,
+	})
+}
+
+func (d *differ) diffOperation(name string, oldOp, newOp *operation) {
+	for _, key := range sortedKeys(oldOp.parameters, newOp.parameters) {
+		oldParam, newParam := oldOp.parameters[key], newOp.parameters[key]
+		switch {
+		case newParam == nil:
+			d.add(ChangeParameterRemoved, false, name, key, "parameter was removed")
+		case oldParam == nil && newParam.Required:
+			d.add(ChangeRequiredParameterAdded, true, name, key, "required parameter was added")
+		case oldParam == nil:
+			d.add(ChangeOptionalParameterAdded, false, name, key, "optional parameter was added")
+		default:
+			if newParam.Required && !oldParam.Required {
+				d.add(ChangeParameterBecameRequired, true, name, key, "parameter became required")
+			}
+			d.diffSchema(name, key, oldParam.Schema, newParam.Schema, directionRequest, 0, nil)
+		}
+	}
+
+	oldBody, newBody := requestBody(oldOp.op), requestBody(newOp.op)
+	if oldBody != nil && newBody != nil {
+		if newBody.Required && !oldBody.Required {
+			d.add(ChangeRequestBodyBecameRequired, true, name, "request", "request body became required")
+		}
+		d.diffContent(name, "request", oldBody.Content, newBody.Content, directionRequest)
+	} else if oldBody == nil && newBody != nil && newBody.Required {
+		d.add(ChangeRequestBodyBecameRequired, true, name, "request", "required request body was added")
+	}
+
+	oldResponses, newResponses := responses(oldOp.op), responses(newOp.op)
+	for _, code := range sortedKeys(oldResponses, newResponses) {
+		oldResp, newResp := oldResponses[code], newResponses[code]
+		location := "response." + code
+		switch {
+		case oldResp == nil:
+		case newResp == nil:
+			// Clients may depend on successful responses, other codes are informational.
+			d.add(ChangeResponseRemoved, strings.HasPrefix(code, "2"), name, location, "response was removed")
+		default:
+			d.diffContent(name, location, oldResp.Content, newResp.Content, directionResponse)
+		}
+	}
+}
+
+func (d *differ) diffContent(name, location string, oldContent, newContent openapi3.Content, direction schemaDirection) {
+	for _, mediaType := range sortedKeys(oldContent, newContent) {
+		oldMedia, newMedia := oldContent[mediaType], newContent[mediaType]
+		mediaLocation := location + "." + mediaType
+		switch {
+		case oldMedia == nil:
+		case newMedia == nil:
+			d.add(ChangeMediaTypeRemoved, true, name, mediaLocation, "media type was removed")
+		default:
+			d.diffSchema(name, mediaLocation, oldMedia.Schema, newMedia.Schema, direction, 0, nil)
+		}
+	}
+}
+
+// diffSchema compares two schemas. Requests may not get stricter, e.g. by requiring
+// new properties, while responses may not drop properties clients rely on.
+func (d *differ) diffSchema(name, location string, oldRef, newRef *openapi3.SchemaRef, direction schemaDirection, depth int, visited map[[2]*openapi3.Schema]bool) {
+	if oldRef == nil || newRef == nil || oldRef.Value == nil || newRef.Value == nil || depth > maxSchemaDepth {
+		return
+	}
+	oldSchema, newSchema := oldRef.Value, newRef.Value
+	// Recursive schemas are only compared once.
+	pair := [2]*openapi3.Schema{oldSchema, newSchema}
+	if visited[pair] {
+		return
+	}
+	if visited == nil {
+		visited = make(map[[2]*openapi3.Schema]bool)
+	}
+	visited[pair] = true
+
+	if oldSchema.Type != "" && newSchema.Type != "" && oldSchema.Type != newSchema.Type {
+		d.add(ChangeTypeChanged, true, name, location,
+			fmt.Sprintf("type changed from %s to %s", oldSchema.Type, newSchema.Type))
+		return
+	}
+
+	if direction == directionRequest && len(oldSchema.Enum) > 0 {
+		for _, value := range oldSchema.Enum {
+			if len(newSchema.Enum) > 0 && !containsValue(newSchema.Enum, value) {
+				d.add(ChangeEnumValueRemoved, true, name, location, fmt.Sprintf("enum value %v was removed", value))
+			}
+		}
+	}
+
+	oldRequired := toSet(oldSchema.Required)
+	newRequired := toSet(newSchema.Required)
+	for _, prop := range sortedKeys(oldSchema.Properties, newSchema.Properties) {
+		oldProp, newProp := oldSchema.Properties[prop], newSchema.Properties[prop]
+		propLocation := joinLocation(location, prop)
+		switch {
+		case oldProp == nil:
+			if direction == directionRequest && newRequired[prop] {
+				d.add(ChangeRequiredPropertyAdded, true, name, propLocation, "required property was added")
+			}
+		case newProp == nil:
+			if direction == directionResponse {
+				d.add(ChangePropertyRemoved, true, name, propLocation, "property was removed")
+			}
+		default:
+			if direction == directionRequest && newRequired[prop] && !oldRequired[prop] {
+				d.add(ChangePropertyBecameRequired, true, name, propLocation, "property became required")
+			}
+			d.diffSchema(name, propLocation, oldProp, newProp, direction, depth+1, visited)
+		}
+	}
+
+	d.diffSchema(name, joinLocation(location, "[]"), oldSchema.Items, newSchema.Items, direction, depth+1, visited)
+}
+
+func requestBody(op *openapi3.Operation) *openapi3.RequestBody {
+	if op.RequestBody == nil {
+		return nil
+	}
+	return op.RequestBody.Value
+}
+
+func responses(op *openapi3.Operation) map[string]*openapi3.Response {
+	resps := make(map[string]*openapi3.Response)
+	if op.Responses == nil {
+		return resps
+	}
+	for code, ref := range op.Responses.Map() {
+		if ref != nil && ref.Value != nil {
+			resps[code] = ref.Value
+		}
+	}
+	return resps
+}
+
+func joinLocation(location, elem string) string {
+	if location == "" {
+		return elem
+	}
+	return location + "." + elem
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if fmt.Sprint(v) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the union of the keys of both maps in order.
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
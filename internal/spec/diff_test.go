@@ -0,0 +1,212 @@
+package spec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const basePets = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/NewPet'}
+      responses:
+        "201": {description: Created}
+  /pets/{id}:
+    delete:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "204": {description: Deleted}
+        "404": {description: Not found}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id: {type: string}
+        name: {type: string}
+        tag: {type: string}
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        kind: {type: string, enum: [cat, dog]}
+`
+
+func TestDiff(t *testing.T) {
+	// change is a Change without its message.
+	type change struct {
+		kind      string
+		breaking  bool
+		operation string
+		location  string
+	}
+	tests := []struct {
+		name string
+		// replacements are applied to basePets in pairs of old and new strings.
+		replacements []string
+		want         []change
+	}{
+		{
+			name: "unchanged",
+		},
+		{
+			name:         "renamed path parameter",
+			replacements: []string{"/pets/{id}:", "/pets/{petId}:", "{name: id, in: path", "{name: petId, in: path"},
+		},
+		{
+			name:         "replaced operation",
+			replacements: []string{"    post:", "    put:"},
+			want: []change{
+				{ChangeOperationRemoved, true, "POST /pets", ""},
+				{ChangeOperationAdded, false, "PUT /pets", ""},
+			},
+		},
+		{
+			name:         "removed operation",
+			replacements: []string{"    delete:", "    x-delete:"},
+			want: []change{
+				{ChangeOperationRemoved, true, "DELETE /pets/{id}", ""},
+			},
+		},
+		{
+			name: "added parameters",
+			replacements: []string{"        - {name: limit", `        - {name: offset, in: query, schema: {type: integer}}
+        - {name: owner, in: query, required: true, schema: {type: string}}
+        - {name: limit`},
+			want: []change{
+				{ChangeOptionalParameterAdded, false, "GET /pets", "query.offset"},
+				{ChangeRequiredParameterAdded, true, "GET /pets", "query.owner"},
+			},
+		},
+		{
+			name:         "removed parameter",
+			replacements: []string{"        - {name: limit, in: query, schema: {type: integer}}\n", ""},
+			want: []change{
+				{ChangeParameterRemoved, false, "GET /pets", "query.limit"},
+			},
+		},
+		{
+			name:         "parameter became required",
+			replacements: []string{"{name: limit, in: query,", "{name: limit, in: query, required: true,"},
+			want: []change{
+				{ChangeParameterBecameRequired, true, "GET /pets", "query.limit"},
+			},
+		},
+		{
+			name:         "request body became required",
+			replacements: []string{"      requestBody:\n", "      requestBody:\n        required: true\n"},
+			want: []change{
+				{ChangeRequestBodyBecameRequired, true, "POST /pets", "request"},
+			},
+		},
+		{
+			name:         "request property became required",
+			replacements: []string{"required: [name]", "required: [name, kind]"},
+			want: []change{
+				{ChangePropertyBecameRequired, true, "POST /pets", "request.application/json.kind"},
+			},
+		},
+		{
+			name:         "required request property added",
+			replacements: []string{"required: [name]", "required: [name, age]", "        kind:", "        age: {type: integer}\n        kind:"},
+			want: []change{
+				{ChangeRequiredPropertyAdded, true, "POST /pets", "request.application/json.age"},
+			},
+		},
+		{
+			name:         "request enum value removed",
+			replacements: []string{"enum: [cat, dog]", "enum: [cat]"},
+			want: []change{
+				{ChangeEnumValueRemoved, true, "POST /pets", "request.application/json.kind"},
+			},
+		},
+		{
+			name:         "response property removed",
+			replacements: []string{"        tag: {type: string}\n", ""},
+			want: []change{
+				{ChangePropertyRemoved, true, "GET /pets", "response.200.application/json.[].tag"},
+			},
+		},
+		{
+			name:         "response property type changed",
+			replacements: []string{"id: {type: string}", "id: {type: integer}"},
+			want: []change{
+				{ChangeTypeChanged, true, "GET /pets", "response.200.application/json.[].id"},
+			},
+		},
+		{
+			name:         "removed responses",
+			replacements: []string{`        "404": {description: Not found}` + "\n", "", `        "204"`, `        "200"`},
+			want: []change{
+				{ChangeResponseRemoved, true, "DELETE /pets/{id}", "response.204"},
+				{ChangeResponseRemoved, false, "DELETE /pets/{id}", "response.404"},
+			},
+		},
+		{
+			name:         "removed media type",
+			replacements: []string{"          content:\n            application/json:", "          content:\n            application/xml:"},
+			want: []change{
+				{ChangeMediaTypeRemoved, true, "GET /pets", "response.200.application/json"},
+			},
+		},
+	}
+
+	base, err := LoadOpenAPI([]byte(basePets))
+	if err != nil {
+		t.Fatalf("Failed to load base spec: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := basePets
+			for i := 0; i < len(tt.replacements); i += 2 {
+				if !strings.Contains(content, tt.replacements[i]) {
+					t.Fatalf("Spec doesn't contain %q", tt.replacements[i])
+				}
+				content = strings.Replace(content, tt.replacements[i], tt.replacements[i+1], 1)
+			}
+			revision, err := LoadOpenAPI([]byte(content))
+			if err != nil {
+				t.Fatalf("Failed to load revised spec: %v", err)
+			}
+
+			changes := Diff(base, revision)
+			got := make([]change, 0, len(changes))
+			for _, c := range changes {
+				assert.NotEmpty(t, c.Message)
+				got = append(got, change{c.Kind, c.Breaking, c.Operation, c.Location})
+			}
+			want := tt.want
+			if want == nil {
+				want = []change{}
+			}
+			assert.Equal(t, want, got)
+
+			breaking := false
+			for _, c := range tt.want {
+				breaking = breaking || c.breaking
+			}
+			assert.Equal(t, breaking, Breaking(changes))
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Package spec parses and validates the API specifications attached to versions.
+package spec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	invopopyaml "github.com/invopop/yaml"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of API specifications.
+const (
+	FormatOpenAPI  = "openapi"
+	FormatAsyncAPI = "asyncapi"
+	FormatProtobuf = "protobuf"
+)
+
+// Encodings of API specifications.
+const (
+	EncodingJSON  = "json"
+	EncodingYAML  = "yaml"
+	EncodingProto = "proto"
+)
+
+// Content types specifications are served with.
+const (
+	ContentTypeOpenAPIJSON  = "application/vnd.oai.openapi+json"
+	ContentTypeOpenAPIYAML  = "application/vnd.oai.openapi"
+	ContentTypeAsyncAPIJSON = "application/json"
+	ContentTypeAsyncAPIYAML = "application/yaml"
+	ContentTypeProtobuf     = "text/x-protobuf"
+)
+
+// ErrInvalid is wrapped by all errors returned for invalid specifications.
+var ErrInvalid = errors.New("invalid spec")
+
+// Spec represents a parsed and validated API specification.
+type Spec struct {
+	Format   string
+	Encoding string
+	Content  []byte
+}
+
+// ContentType returns the content type the specification should be served with.
+func (s *Spec) ContentType() string {
+	return ContentType(s.Format, s.Encoding)
+}
+
+// ContentType returns the content type of a specification in the provided format and encoding.
+func ContentType(format, encoding string) string {
+	switch {
+	case format == FormatProtobuf:
+		return ContentTypeProtobuf
+	case format == FormatOpenAPI && encoding == EncodingJSON:
+		return ContentTypeOpenAPIJSON
+	case format == FormatOpenAPI:
+		return ContentTypeOpenAPIYAML
+	case encoding == EncodingJSON:
+		return ContentTypeAsyncAPIJSON
+	default:
+		return ContentTypeAsyncAPIYAML
+	}
+}
+
+// Parse detects the format of the specification and validates it. OpenAPI and
+// AsyncAPI documents are recognized by their 'openapi'/'swagger' and 'asyncapi'
+// fields; anything else must be a protobuf definition. format, if not empty,
+// must match the detected format.
+func Parse(content []byte, format string) (*Spec, error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, fmt.Errorf("%w: empty document", ErrInvalid)
+	}
+	switch format {
+	case "", FormatOpenAPI, FormatAsyncAPI, FormatProtobuf:
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalid, format)
+	}
+
+	s := &Spec{Content: content}
+	doc, encoding, err := decodeDocument(content)
+	if err != nil || doc == nil {
+		s.Format = FormatProtobuf
+		s.Encoding = EncodingProto
+	} else {
+		s.Encoding = encoding
+		switch {
+		case doc["openapi"] != nil || doc["swagger"] != nil:
+			s.Format = FormatOpenAPI
+		case doc["asyncapi"] != nil:
+			s.Format = FormatAsyncAPI
+		default:
+			return nil, fmt.Errorf("%w: document has neither an 'openapi', 'swagger' nor 'asyncapi' field", ErrInvalid)
+		}
+	}
+	if format != "" && format != s.Format {
+		return nil, fmt.Errorf("%w: expected a %s spec, but got a %s spec", ErrInvalid, format, s.Format)
+	}
+
+	switch s.Format {
+	case FormatOpenAPI:
+		err = validateOpenAPI(content, doc)
+	case FormatAsyncAPI:
+		err = validateAsyncAPI(doc)
+	default:
+		err = validateProtobuf(content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+	}
+	return s, nil
+}
+
+// decodeDocument decodes a JSON or YAML document into a map. YAML is a superset
+// of JSON, but JSON documents are reported as such so that they can be served back
+// with the right content type.
+func decodeDocument(content []byte) (map[string]interface{}, string, error) {
+	var doc map[string]interface{}
+	if json.Valid(content) {
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, "", err
+		}
+		return doc, EncodingJSON, nil
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, "", err
+	}
+	return doc, EncodingYAML, nil
+}
+
+// LoadOpenAPI loads an OpenAPI document. Swagger 2.0 documents are converted to OpenAPI 3.
+// External references are not resolved.
+func LoadOpenAPI(content []byte) (*openapi3.T, error) {
+	var doc map[string]interface{}
+	if err := decodeInto(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc["swagger"] != nil {
+		var v2 openapi2.T
+		if err := decodeInto(content, &v2); err != nil {
+			return nil, err
+		}
+		return openapi2conv.ToV3(&v2)
+	}
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = false
+	return loader.LoadFromData(content)
+}
+
+func validateOpenAPI(content []byte, doc map[string]interface{}) error {
+	if v, ok := doc["openapi"].(string); ok && !strings.HasPrefix(v, "3.") {
+		return fmt.Errorf("unsupported OpenAPI version %q", v)
+	}
+	if v, ok := doc["swagger"]; ok && fmt.Sprint(v) != "2.0" {
+		return fmt.Errorf("unsupported Swagger version %q", fmt.Sprint(v))
+	}
+	t, err := LoadOpenAPI(content)
+	if err != nil {
+		return err
+	}
+	return t.Validate(context.Background())
+}
+
+func validateAsyncAPI(doc map[string]interface{}) error {
+	version, ok := doc["asyncapi"].(string)
+	if !ok || !(strings.HasPrefix(version, "2.") || strings.HasPrefix(version, "3.")) {
+		return fmt.Errorf("unsupported AsyncAPI version %q", fmt.Sprint(doc["asyncapi"]))
+	}
+	info, ok := doc["info"].(map[string]interface{})
+	if !ok {
+		return errors.New("'info' is required")
+	}
+	for _, field := range []string{"title", "version"} {
+		if v, ok := info[field].(string); !ok || v == "" {
+			return fmt.Errorf("'info.%s' is required", field)
+		}
+	}
+	if channels, ok := doc["channels"]; ok {
+		if _, ok := channels.(map[string]interface{}); !ok {
+			return errors.New("'channels' must be an object")
+		}
+	} else if strings.HasPrefix(version, "2.") {
+		return errors.New("'channels' is required")
+	}
+	return nil
+}
+
+var (
+	protoSyntax      = regexp.MustCompile(`(?m)^\s*(syntax|edition)\s*=\s*"[^"]+"\s*;`)
+	protoDeclaration = regexp.MustCompile(`(?m)^\s*(message|service|enum)\s+[A-Za-z_][A-Za-z0-9_]*\s*\{`)
+	protoComments    = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+	protoStrings     = regexp.MustCompile(`"(\\.|[^"\\])*"|'(\\.|[^'\\])*'`)
+)
+
+// validateProtobuf performs a lightweight check of a protobuf definition: it must
+// declare its syntax, contain at least one message, service or enum, and have
+// balanced braces.
+func validateProtobuf(content []byte) error {
+	withoutComments := protoComments.ReplaceAll(content, nil)
+	if !protoSyntax.Match(withoutComments) {
+		return errors.New(`not a JSON or YAML document, nor a protobuf definition with a 'syntax = "proto3";' statement`)
+	}
+	// Strings may contain braces, so they are emptied before counting.
+	src := protoStrings.ReplaceAll(withoutComments, []byte(`""`))
+	if !protoDeclaration.Match(src) {
+		return errors.New("protobuf definition doesn't declare any message, service or enum")
+	}
+	depth := 0
+	for _, b := range src {
+		switch b {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+		if depth < 0 {
+			return errors.New("protobuf definition has unbalanced braces")
+		}
+	}
+	if depth != 0 {
+		return errors.New("protobuf definition has unbalanced braces")
+	}
+	return nil
+}
+
+// decodeInto decodes a JSON or YAML document into v, using v's JSON field tags.
+func decodeInto(content []byte, v interface{}) error {
+	if json.Valid(content) {
+		return json.Unmarshal(content, v)
+	}
+	// Unlike yaml.v3, this converts keys such as response codes to strings.
+	return invopopyaml.Unmarshal(content, v)
+}
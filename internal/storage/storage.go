@@ -0,0 +1,102 @@
+// Package storage provides stores for blobs, like the API specifications of versions.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned if no blob exists for a key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores blobs by key. Keys are slash separated paths, like
+// 'specs/1/2/<sha256>'. Implementations must be safe for concurrent use.
+type BlobStore interface {
+	// Put stores the blob under the key, replacing any existing one.
+	Put(ctx context.Context, key string, content []byte) error
+	// Get returns a reader for the blob stored under the key, or ErrNotFound.
+	// The caller must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete deletes the blob stored under the key. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// FilesystemStore is a BlobStore which stores each blob as a file under a directory.
+type FilesystemStore struct {
+	dir string
+}
+
+// NewFilesystemStore returns a FilesystemStore which stores blobs under dir,
+// creating it if it doesn't exist.
+func NewFilesystemStore(dir string) (*FilesystemStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("unable to create blob directory: %w", err)
+	}
+	return &FilesystemStore{dir: dir}, nil
+}
+
+func (f *FilesystemStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid blob key: %q", key)
+	}
+	return filepath.Join(f.dir, clean), nil
+}
+
+// Put implements BlobStore. The blob is written to a temporary file first, so
+// that readers never see a partially written blob.
+func (f *FilesystemStore) Put(_ context.Context, key string, content []byte) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get implements BlobStore.
+func (f *FilesystemStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// Delete implements BlobStore.
+func (f *FilesystemStore) Delete(_ context.Context, key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
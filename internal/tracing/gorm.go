@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"errors"
+	"regexp"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+var (
+	stringLiteralPattern  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numericLiteralPattern = regexp.MustCompile(`\$?\b\d+(?:\.\d+)?\b`)
+)
+
+// GormPlugin is a GORM plugin creating a span for every query, as a child of the
+// span in the query's context.
+type GormPlugin struct{}
+
+// Name implements gorm.Plugin.
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize implements gorm.Plugin, registering callbacks which run first and
+// last for every kind of operation.
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	registrations := []error{
+		callbacks.Create().Before("*").Register("tracing:before_create", startSpan("create")),
+		callbacks.Create().After("*").Register("tracing:after_create", endSpan),
+		callbacks.Query().Before("*").Register("tracing:before_query", startSpan("query")),
+		callbacks.Query().After("*").Register("tracing:after_query", endSpan),
+		callbacks.Update().Before("*").Register("tracing:before_update", startSpan("update")),
+		callbacks.Update().After("*").Register("tracing:after_update", endSpan),
+		callbacks.Delete().Before("*").Register("tracing:before_delete", startSpan("delete")),
+		callbacks.Delete().After("*").Register("tracing:after_delete", endSpan),
+		callbacks.Row().Before("*").Register("tracing:before_row", startSpan("row")),
+		callbacks.Row().After("*").Register("tracing:after_row", endSpan),
+		callbacks.Raw().Before("*").Register("tracing:before_raw", startSpan("raw")),
+		callbacks.Raw().After("*").Register("tracing:after_raw", endSpan),
+	}
+	return errors.Join(registrations...)
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := Tracer().Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperation(operation)))
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	v, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+	if !span.IsRecording() {
+		return
+	}
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBSQLTable(db.Statement.Table))
+	}
+	span.SetAttributes(semconv.DBStatement(SanitizeSQL(db.Statement.SQL.String())))
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
+
+// SanitizeSQL replaces the string and numeric literals in the SQL statement with
+// '?', so that values inlined into a statement aren't recorded. Placeholders like
+// '$1' are kept.
+func SanitizeSQL(sql string) string {
+	sql = stringLiteralPattern.ReplaceAllString(sql, "?")
+	return numericLiteralPattern.ReplaceAllStringFunc(sql, func(literal string) string {
+		if literal[0] == '$' {
+			return literal
+		}
+		return "?"
+	})
+}
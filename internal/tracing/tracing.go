@@ -0,0 +1,105 @@
+// Package tracing configures OpenTelemetry tracing and instruments database queries.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aryan9600/service-catalog/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TracerName is the name of the tracer used for all spans of the service.
+	TracerName = "github.com/aryan9600/service-catalog"
+
+	serviceName = "service-catalog"
+)
+
+var (
+	provider *sdktrace.TracerProvider
+	// output is the file spans are written to by the file exporter.
+	output io.Closer
+)
+
+// SetTracingConfig installs the global tracer provider and W3C trace context
+// propagator. The exporter is one of:
+//
+//   - 'none': spans aren't exported, but incoming trace contexts are still
+//     propagated.
+//   - 'stdout': spans are written to stdout as JSON.
+//   - 'file': spans are written as JSON to cfg.File.
+//   - 'otlp': spans are sent over OTLP/HTTP, configured by the standard
+//     OTEL_EXPORTER_OTLP_* env vars.
+//
+// cfg.SampleRatio is the ratio of traces sampled, unless the caller already decided
+// whether the trace is sampled.
+func SetTracingConfig(cfg config.Tracing) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "none":
+		return nil
+	case "stdout":
+		exporter, err = stdouttrace.New()
+	case "file":
+		f, openErr := os.OpenFile(cfg.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+		if openErr != nil {
+			return fmt.Errorf("unable to open trace file: %w", openErr)
+		}
+		output = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	case "otlp":
+		exporter, err = otlptracehttp.New(context.Background())
+	default:
+		return fmt.Errorf("invalid trace exporter: %s; must be one of: none, stdout, file, otlp", cfg.Exporter)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to create trace exporter: %w", err)
+	}
+
+	res, err := resource.New(context.Background(),
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create trace resource: %w", err)
+	}
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+// Shutdown exports the remaining spans and stops the tracer provider.
+func Shutdown(ctx context.Context) error {
+	var errs []error
+	if provider != nil {
+		errs = append(errs, provider.Shutdown(ctx))
+	}
+	if output != nil {
+		errs = append(errs, output.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Tracer returns the tracer used for all spans of the service.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}